@@ -0,0 +1,119 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path"
+)
+
+// ChannelAdapter translates components into the native payloads of a messaging platform
+// It is used to send messages directly to a channel, without the Recast.AI connector
+type ChannelAdapter interface {
+	// Adapt returns the payloads to send to the platform API for messages,
+	// a message can result in several payloads
+	Adapt(messages ...Component) ([]json.RawMessage, error)
+}
+
+// AdapterFor returns the adapter of a channel type
+// ok is false if there is no adapter for the channel
+func AdapterFor(channel string) (adapter ChannelAdapter, ok bool) {
+	switch channel {
+	case ChannelMessenger:
+		return MessengerAdapter{}, true
+	case ChannelSlack, ChannelSlackWebhook:
+		return SlackAdapter{}, true
+	case ChannelTelegram:
+		return TelegramAdapter{}, true
+	case ChannelBotFramework:
+		return BotFrameworkAdapter{}, true
+	}
+	return nil, false
+}
+
+// jsonObject is a JSON object of a native payload
+type jsonObject map[string]interface{}
+
+// adaptMessages calls adaptComponent on every message and encodes the payloads
+func adaptMessages(platform string, messages []Component, adaptComponent func(Component) ([]jsonObject, error)) ([]json.RawMessage, error) {
+	var payloads []json.RawMessage
+	for _, message := range messages {
+		objects, err := adaptComponent(message)
+		if err != nil {
+			return nil, err
+		}
+		if objects == nil {
+			return nil, fmt.Errorf("Cannot adapt component of type %T (%q) for %s", message, componentType(message), platform)
+		}
+		for _, object := range objects {
+			data, err := json.Marshal(object)
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, data)
+		}
+	}
+	return payloads, nil
+}
+
+// mediaTypes are the types of the components holding a media
+var mediaTypes = map[string]bool{
+	"picture": true,
+	"video":   true,
+	"audio":   true,
+	"file":    true,
+}
+
+// mediaOf returns the type and URL of the components holding a media
+// Attachments of the other types are not media, the adapters reject them
+func mediaOf(c Component) (typ, url string, ok bool) {
+	switch c := c.(type) {
+	case Attachment:
+		typ, url = c.Type, c.Content
+	case *Attachment:
+		typ, url = c.Type, c.Content
+	case *Audio:
+		typ, url = c.Type, c.Content
+	case *File:
+		typ, url = c.Type, c.Content
+	}
+	return typ, url, mediaTypes[typ]
+}
+
+// textOf returns the text of the text components
+func textOf(c Component) (string, bool) {
+	switch c := c.(type) {
+	case Attachment:
+		return c.Content, c.Type == "text"
+	case *Attachment:
+		return c.Content, c.Type == "text"
+	}
+	return "", false
+}
+
+// mediaContentType guesses the content type of a media from its URL
+func mediaContentType(typ, url string) string {
+	if contentType := mime.TypeByExtension(path.Ext(url)); contentType != "" {
+		return contentType
+	}
+	switch typ {
+	case "picture":
+		return "image/*"
+	case "video":
+		return "video/*"
+	case "audio":
+		return "audio/*"
+	}
+	return "application/octet-stream"
+}
+
+// cardTitle joins the title and subtitle of a card
+func cardTitle(title, subtitle string) string {
+	if subtitle == "" {
+		return title
+	}
+	if title == "" {
+		return subtitle
+	}
+	return title + "\n" + subtitle
+}
@@ -0,0 +1,94 @@
+package recast
+
+import (
+	"encoding/json"
+)
+
+// BotFrameworkAdapter translates components into Microsoft Bot Framework activities
+// Cards are sent as Hero cards, and quick replies as suggested actions
+type BotFrameworkAdapter struct{}
+
+// Adapt implements ChannelAdapter
+func (a BotFrameworkAdapter) Adapt(messages ...Component) ([]json.RawMessage, error) {
+	return adaptMessages("Bot Framework", messages, a.adapt)
+}
+
+func (a BotFrameworkAdapter) adapt(c Component) ([]jsonObject, error) {
+	if text, ok := textOf(c); ok {
+		return []jsonObject{a.message(jsonObject{"text": text})}, nil
+	}
+	if typ, url, ok := mediaOf(c); ok {
+		attachment := jsonObject{"contentType": mediaContentType(typ, url), "contentUrl": url}
+		return []jsonObject{a.message(jsonObject{"attachments": []jsonObject{attachment}})}, nil
+	}
+
+	switch c := c.(type) {
+	case *Card:
+		card := a.heroCard(c.Content.Title, c.Content.Subtitle, c.Content.ImageURL, c.Content.Buttons)
+		return []jsonObject{a.message(jsonObject{"attachments": []jsonObject{card}})}, nil
+	case *Carousel:
+		cards := make([]jsonObject, len(c.Content))
+		for i, card := range c.Content {
+			cards[i] = a.heroCard(card.Title, card.Subtitle, card.ImageURL, card.Buttons)
+		}
+		return []jsonObject{a.message(jsonObject{"attachmentLayout": "carousel", "attachments": cards})}, nil
+	case *List:
+		var cards []jsonObject
+		for _, e := range c.Content.Elements {
+			cards = append(cards, a.heroCard(e.Title, e.Subtitle, e.ImageURL, listButtons(e.Buttons)))
+		}
+		if len(c.Content.Buttons) > 0 {
+			cards = append(cards, a.heroCard("", "", "", listButtons(c.Content.Buttons)))
+		}
+		return []jsonObject{a.message(jsonObject{"attachmentLayout": "list", "attachments": cards})}, nil
+	case *Buttons:
+		card := a.heroCard("", "", "", c.Content.Buttons)
+		card["content"].(jsonObject)["text"] = c.Content.Title
+		return []jsonObject{a.message(jsonObject{"attachments": []jsonObject{card}})}, nil
+	case *QuickReplies:
+		actions := make([]jsonObject, len(c.Content.Buttons))
+		for i, b := range c.Content.Buttons {
+			actions[i] = jsonObject{"type": "imBack", "title": b.Title, "value": b.Value}
+		}
+		return []jsonObject{a.message(jsonObject{"text": c.Content.Title, "suggestedActions": jsonObject{"actions": actions}})}, nil
+	case *Delay:
+		return []jsonObject{{"type": "typing"}}, nil
+	}
+	return nil, nil
+}
+
+func (a BotFrameworkAdapter) message(activity jsonObject) jsonObject {
+	activity["type"] = "message"
+	return activity
+}
+
+func (a BotFrameworkAdapter) heroCard(title, subtitle, imageURL string, buttons []CardButton) jsonObject {
+	content := jsonObject{}
+	if title != "" {
+		content["title"] = title
+	}
+	if subtitle != "" {
+		content["subtitle"] = subtitle
+	}
+	if imageURL != "" {
+		content["images"] = []jsonObject{{"url": imageURL}}
+	}
+
+	var actions []jsonObject
+	for _, b := range buttons {
+		switch b.Kind() {
+		case ButtonPostback:
+			actions = append(actions, jsonObject{"type": "postBack", "title": b.Title, "value": b.Value})
+		case ButtonURL:
+			actions = append(actions, jsonObject{"type": "openUrl", "title": b.Title, "value": b.Value})
+		case ButtonPhone:
+			actions = append(actions, jsonObject{"type": "call", "title": b.Title, "value": "tel:" + b.Value})
+		case ButtonLogin:
+			actions = append(actions, jsonObject{"type": "signin", "title": b.Title, "value": b.Value})
+		}
+	}
+	if len(actions) > 0 {
+		content["buttons"] = actions
+	}
+	return jsonObject{"contentType": "application/vnd.microsoft.card.hero", "content": content}
+}
@@ -0,0 +1,111 @@
+package recast
+
+import (
+	"encoding/json"
+)
+
+// MessengerAdapter translates components into Facebook Messenger Send API requests
+// The recipient has to be added to the payloads before they are sent
+type MessengerAdapter struct{}
+
+// Adapt implements ChannelAdapter
+func (a MessengerAdapter) Adapt(messages ...Component) ([]json.RawMessage, error) {
+	return adaptMessages(ChannelMessenger, messages, a.adapt)
+}
+
+var messengerMediaTypes = map[string]string{
+	"picture": "image",
+	"video":   "video",
+	"audio":   "audio",
+	"file":    "file",
+}
+
+func (a MessengerAdapter) adapt(c Component) ([]jsonObject, error) {
+	if text, ok := textOf(c); ok {
+		return a.message(jsonObject{"text": text}), nil
+	}
+	if typ, url, ok := mediaOf(c); ok {
+		return a.message(jsonObject{"attachment": jsonObject{
+			"type":    messengerMediaTypes[typ],
+			"payload": jsonObject{"url": url, "is_reusable": true},
+		}}), nil
+	}
+
+	switch c := c.(type) {
+	case *Card:
+		element := a.element(c.Content.Title, c.Content.Subtitle, c.Content.ImageURL, c.Content.Buttons)
+		return a.template(jsonObject{"template_type": "generic", "elements": []jsonObject{element}}), nil
+	case *Carousel:
+		elements := make([]jsonObject, len(c.Content))
+		for i, card := range c.Content {
+			elements[i] = a.element(card.Title, card.Subtitle, card.ImageURL, card.Buttons)
+		}
+		return a.template(jsonObject{"template_type": "generic", "elements": elements}), nil
+	case *List:
+		elements := make([]jsonObject, len(c.Content.Elements))
+		for i, e := range c.Content.Elements {
+			elements[i] = a.element(e.Title, e.Subtitle, e.ImageURL, listButtons(e.Buttons))
+		}
+		payload := jsonObject{"template_type": "list", "top_element_style": "compact", "elements": elements}
+		if len(c.Content.Buttons) > 0 {
+			payload["buttons"] = a.buttons(listButtons(c.Content.Buttons))
+		}
+		return a.template(payload), nil
+	case *Buttons:
+		return a.template(jsonObject{
+			"template_type": "button",
+			"text":          c.Content.Title,
+			"buttons":       a.buttons(c.Content.Buttons),
+		}), nil
+	case *QuickReplies:
+		replies := make([]jsonObject, len(c.Content.Buttons))
+		for i, b := range c.Content.Buttons {
+			replies[i] = jsonObject{"content_type": "text", "title": b.Title, "payload": b.Value}
+		}
+		return a.message(jsonObject{"text": c.Content.Title, "quick_replies": replies}), nil
+	case *Delay:
+		return []jsonObject{{"sender_action": "typing_on"}}, nil
+	}
+	return nil, nil
+}
+
+func (a MessengerAdapter) message(message jsonObject) []jsonObject {
+	return []jsonObject{{"message": message}}
+}
+
+func (a MessengerAdapter) template(payload jsonObject) []jsonObject {
+	return a.message(jsonObject{"attachment": jsonObject{"type": "template", "payload": payload}})
+}
+
+func (a MessengerAdapter) element(title, subtitle, imageURL string, buttons []CardButton) jsonObject {
+	element := jsonObject{"title": title}
+	if subtitle != "" {
+		element["subtitle"] = subtitle
+	}
+	if imageURL != "" {
+		element["image_url"] = imageURL
+	}
+	if len(buttons) > 0 {
+		element["buttons"] = a.buttons(buttons)
+	}
+	return element
+}
+
+func (a MessengerAdapter) buttons(buttons []CardButton) []jsonObject {
+	converted := make([]jsonObject, 0, len(buttons))
+	for _, b := range buttons {
+		switch b.Kind() {
+		case ButtonPostback:
+			converted = append(converted, jsonObject{"type": "postback", "title": b.Title, "payload": b.Value})
+		case ButtonURL:
+			converted = append(converted, jsonObject{"type": "web_url", "title": b.Title, "url": b.Value})
+		case ButtonPhone:
+			converted = append(converted, jsonObject{"type": "phone_number", "title": b.Title, "payload": b.Value})
+		case ButtonShare:
+			converted = append(converted, jsonObject{"type": "element_share"})
+		case ButtonLogin:
+			converted = append(converted, jsonObject{"type": "account_link", "url": b.Value})
+		}
+	}
+	return converted
+}
@@ -0,0 +1,110 @@
+package recast
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// SlackAdapter translates components into Slack Block Kit messages
+// The channel has to be added to the payloads before they are sent with chat.postMessage
+type SlackAdapter struct{}
+
+// Adapt implements ChannelAdapter
+func (a SlackAdapter) Adapt(messages ...Component) ([]json.RawMessage, error) {
+	return adaptMessages(ChannelSlack, messages, a.adapt)
+}
+
+func (a SlackAdapter) adapt(c Component) ([]jsonObject, error) {
+	// text is the notification fallback of the blocks
+	fallback, _ := RenderText(c)
+	fallback = slackEscape(fallback)
+
+	var blocks []jsonObject
+	if text, ok := textOf(c); ok {
+		blocks = append(blocks, a.section(slackEscape(text)))
+	} else if typ, url, ok := mediaOf(c); ok {
+		if typ == "picture" {
+			blocks = append(blocks, jsonObject{"type": "image", "image_url": url, "alt_text": typ})
+		} else {
+			blocks = append(blocks, a.section("<"+slackEscape(url)+">"))
+		}
+	} else {
+		switch c := c.(type) {
+		case *Card:
+			blocks = a.card(blocks, c.Content.Title, c.Content.Subtitle, c.Content.ImageURL, c.Content.Buttons)
+		case *Carousel:
+			for i, card := range c.Content {
+				if i > 0 {
+					blocks = append(blocks, jsonObject{"type": "divider"})
+				}
+				blocks = a.card(blocks, card.Title, card.Subtitle, card.ImageURL, card.Buttons)
+			}
+		case *List:
+			for _, e := range c.Content.Elements {
+				blocks = a.card(blocks, e.Title, e.Subtitle, e.ImageURL, listButtons(e.Buttons))
+			}
+			blocks = a.actions(blocks, listButtons(c.Content.Buttons))
+		case *Buttons:
+			blocks = append(blocks, a.section(slackEscape(c.Content.Title)))
+			blocks = a.actions(blocks, c.Content.Buttons)
+		case *QuickReplies:
+			buttons := make([]CardButton, len(c.Content.Buttons))
+			for i, b := range c.Content.Buttons {
+				buttons[i] = PostbackButton(b.Title, b.Value)
+			}
+			blocks = append(blocks, a.section(slackEscape(c.Content.Title)))
+			blocks = a.actions(blocks, buttons)
+		case *Delay:
+			return []jsonObject{}, nil
+		default:
+			return nil, nil
+		}
+	}
+	return []jsonObject{{"text": fallback, "blocks": blocks}}, nil
+}
+
+// slackEscape escapes the control characters of mrkdwn texts
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// section returns a block of mrkdwn text, text must already be escaped
+func (a SlackAdapter) section(text string) jsonObject {
+	return jsonObject{"type": "section", "text": jsonObject{"type": "mrkdwn", "text": text}}
+}
+
+func (a SlackAdapter) card(blocks []jsonObject, title, subtitle, imageURL string, buttons []CardButton) []jsonObject {
+	text := "*" + slackEscape(title) + "*"
+	if subtitle != "" {
+		text += "\n" + slackEscape(subtitle)
+	}
+	section := a.section(text)
+	if imageURL != "" {
+		section["accessory"] = jsonObject{"type": "image", "image_url": imageURL, "alt_text": title}
+	}
+	return a.actions(append(blocks, section), buttons)
+}
+
+// actions appends an actions block with the buttons supported by Slack
+func (a SlackAdapter) actions(blocks []jsonObject, buttons []CardButton) []jsonObject {
+	var elements []jsonObject
+	for _, b := range buttons {
+		element := jsonObject{
+			"type":      "button",
+			"text":      jsonObject{"type": "plain_text", "text": b.Title},
+			"action_id": "button_" + strconv.Itoa(len(blocks)) + "_" + strconv.Itoa(len(elements)),
+		}
+		switch b.Kind() {
+		case ButtonPostback:
+			element["value"] = b.Value
+		case ButtonURL, ButtonLogin:
+			element["url"] = b.Value
+		default:
+			continue
+		}
+		elements = append(elements, element)
+	}
+	if len(elements) == 0 {
+		return blocks
+	}
+	return append(blocks, jsonObject{"type": "actions", "elements": elements})
+}
@@ -0,0 +1,92 @@
+package recast
+
+import (
+	"encoding/json"
+)
+
+// TelegramAdapter translates components into Telegram Bot API requests
+// The method field of the payloads is the Bot API method to call,
+// and the chat_id has to be added before they are sent
+type TelegramAdapter struct{}
+
+// Adapt implements ChannelAdapter
+func (a TelegramAdapter) Adapt(messages ...Component) ([]json.RawMessage, error) {
+	return adaptMessages(ChannelTelegram, messages, a.adapt)
+}
+
+var telegramMediaMethods = map[string][2]string{
+	"picture": {"sendPhoto", "photo"},
+	"video":   {"sendVideo", "video"},
+	"audio":   {"sendAudio", "audio"},
+	"file":    {"sendDocument", "document"},
+}
+
+func (a TelegramAdapter) adapt(c Component) ([]jsonObject, error) {
+	if text, ok := textOf(c); ok {
+		return []jsonObject{{"method": "sendMessage", "text": text}}, nil
+	}
+	if typ, url, ok := mediaOf(c); ok {
+		method := telegramMediaMethods[typ]
+		return []jsonObject{{"method": method[0], method[1]: url}}, nil
+	}
+
+	switch c := c.(type) {
+	case *Card:
+		return []jsonObject{a.card(c.Content.Title, c.Content.Subtitle, c.Content.ImageURL, c.Content.Buttons)}, nil
+	case *Carousel:
+		payloads := make([]jsonObject, len(c.Content))
+		for i, card := range c.Content {
+			payloads[i] = a.card(card.Title, card.Subtitle, card.ImageURL, card.Buttons)
+		}
+		return payloads, nil
+	case *List:
+		payloads := make([]jsonObject, len(c.Content.Elements))
+		for i, e := range c.Content.Elements {
+			buttons := listButtons(e.Buttons)
+			if i == len(c.Content.Elements)-1 {
+				// the list buttons are attached to the last element
+				buttons = append(buttons, listButtons(c.Content.Buttons)...)
+			}
+			payloads[i] = a.card(e.Title, e.Subtitle, e.ImageURL, buttons)
+		}
+		return payloads, nil
+	case *Buttons:
+		return []jsonObject{a.withKeyboard(jsonObject{"method": "sendMessage", "text": c.Content.Title}, c.Content.Buttons)}, nil
+	case *QuickReplies:
+		buttons := make([]CardButton, len(c.Content.Buttons))
+		for i, b := range c.Content.Buttons {
+			buttons[i] = PostbackButton(b.Title, b.Value)
+		}
+		return []jsonObject{a.withKeyboard(jsonObject{"method": "sendMessage", "text": c.Content.Title}, buttons)}, nil
+	case *Delay:
+		return []jsonObject{{"method": "sendChatAction", "action": "typing"}}, nil
+	}
+	return nil, nil
+}
+
+// card returns a photo with a caption, or a text message if there is no image
+func (a TelegramAdapter) card(title, subtitle, imageURL string, buttons []CardButton) jsonObject {
+	payload := jsonObject{"method": "sendMessage", "text": cardTitle(title, subtitle)}
+	if imageURL != "" {
+		payload = jsonObject{"method": "sendPhoto", "photo": imageURL, "caption": cardTitle(title, subtitle)}
+	}
+	return a.withKeyboard(payload, buttons)
+}
+
+// withKeyboard adds an inline keyboard with one button per row
+// Telegram does not support phone and share buttons, they are left out
+func (a TelegramAdapter) withKeyboard(payload jsonObject, buttons []CardButton) jsonObject {
+	var rows [][]jsonObject
+	for _, b := range buttons {
+		switch b.Kind() {
+		case ButtonPostback:
+			rows = append(rows, []jsonObject{{"text": b.Title, "callback_data": b.Value}})
+		case ButtonURL, ButtonLogin:
+			rows = append(rows, []jsonObject{{"text": b.Title, "url": b.Value}})
+		}
+	}
+	if len(rows) > 0 {
+		payload["reply_markup"] = jsonObject{"inline_keyboard": rows}
+	}
+	return payload
+}
@@ -0,0 +1,125 @@
+package recast
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the adapters")
+
+func adapterTestMessages() []Component {
+	return []Component{
+		NewTextMessage("Hello"),
+		Attachment{Type: "picture", Content: "https://recast.ai/logo.png"},
+		NewFile("https://recast.ai/doc.pdf"),
+		NewCard("Recast.AI", "Bots for all").
+			AddImage("https://recast.ai/card.png").
+			AddButtons(URLButton("Visit", "https://recast.ai"), PostbackButton("Subscribe", "SUBSCRIBE"), PhoneButton("Call", "+33600000000")),
+		NewCarousel().
+			AddCard(NewCarouselCard("First", "").AddButtons(PostbackButton("Pick", "FIRST"))).
+			AddCard(NewCarouselCard("Second", "").AddImage("https://recast.ai/second.png")),
+		NewList().
+			AddElement(NewListElement("Element 1", "Subtitle").AddButtons(PostbackButton("Buy", "BUY_1"))).
+			AddElement(NewListElement("Element 2", "")).
+			AddButtons(PostbackButton("More", "MORE")),
+		NewButtons("What do you want to do?").AddButtons(PostbackButton("Talk", "TALK"), LoginButton("Log in", "https://recast.ai/login")),
+		NewQuickReplies("Do you like it?").AddButton("Yes", "yes").AddButton("No", "no"),
+		NewDelay(time.Second),
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	adapters := map[string]ChannelAdapter{
+		"messenger":    MessengerAdapter{},
+		"slack":        SlackAdapter{},
+		"telegram":     TelegramAdapter{},
+		"botframework": BotFrameworkAdapter{},
+	}
+
+	for name, adapter := range adapters {
+		payloads, err := adapter.Adapt(adapterTestMessages()...)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, but instead got %+v", name, err)
+		}
+		data, err := json.MarshalIndent(payloads, "", "  ")
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, but instead got %+v", name, err)
+		}
+		data = append(data, '\n')
+
+		golden := filepath.Join("test", "golden", name+".json")
+		if *updateGolden {
+			if err := ioutil.WriteFile(golden, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, but instead got %+v", name, err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("%s: the payloads do not match %s, run the tests with -update if the change is expected\n%s", name, golden, data)
+		}
+	}
+}
+
+func TestSlackAdapterEscaping(t *testing.T) {
+	payloads, err := (SlackAdapter{}).Adapt(
+		NewTextMessage("Tom & Jerry <3"),
+		NewCard("R&D", "<b>"),
+		NewAudio("https://example.com/listen?v=1&t=2"),
+	)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	expected := []string{`Tom &amp; Jerry &lt;3`, "*R&amp;D*\n&lt;b&gt;", `<https://example.com/listen?v=1&amp;t=2>`}
+	for i, payload := range payloads {
+		var message struct {
+			Blocks []struct {
+				Text struct {
+					Text string `json:"text"`
+				} `json:"text"`
+			} `json:"blocks"`
+		}
+		if err := json.Unmarshal(payload, &message); err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if message.Blocks[0].Text.Text != expected[i] {
+			t.Errorf("Expected %q, but instead got %q", expected[i], message.Blocks[0].Text.Text)
+		}
+	}
+}
+
+func TestAdapterUnknownComponent(t *testing.T) {
+	if _, err := (MessengerAdapter{}).Adapt(&RawComponent{Type: "hologram"}); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	for _, adapter := range []ChannelAdapter{MessengerAdapter{}, SlackAdapter{}, TelegramAdapter{}, BotFrameworkAdapter{}} {
+		for _, c := range []Component{Attachment{Type: "sticker", Content: "https://example.com/sticker.png"}, &Attachment{}, &File{}} {
+			if payloads, err := adapter.Adapt(c); err == nil {
+				t.Fatalf("%T: expected err not to be nil for %+v, but instead got %s", adapter, c, payloads)
+			}
+		}
+	}
+	if _, ok := AdapterFor(ChannelKik); ok {
+		t.Fatal("There is no adapter for kik")
+	}
+	for _, channel := range []string{ChannelSlack, ChannelSlackWebhook} {
+		if adapter, ok := AdapterFor(channel); !ok || adapter != (SlackAdapter{}) {
+			t.Fatalf("Expected the Slack adapter for %s, but instead got %v", channel, adapter)
+		}
+	}
+	if adapter, ok := AdapterFor(ChannelTelegram); !ok || adapter == nil {
+		t.Fatal("Expected a telegram adapter")
+	}
+	if adapter, ok := AdapterFor(ChannelBotFramework); !ok || adapter != (BotFrameworkAdapter{}) {
+		t.Fatal("Expected a Bot Framework adapter")
+	}
+}
@@ -0,0 +1,240 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InboundAttachment is implemented by every attachment a user can send to the bot
+// through the connector. Use a type switch to handle each kind of content:
+//	switch a := message.Content.(type) {
+//	case *recast.InboundText:
+//		fmt.Println("text:", a.Text)
+//	case *recast.InboundLocation:
+//		fmt.Println("location:", a.Lat, a.Lng)
+//	case *recast.InboundPostback:
+//		fmt.Println("button clicked:", a.Value)
+//	}
+type InboundAttachment interface {
+	// AttachmentType returns the type of the attachment as sent by the connector
+	AttachmentType() string
+	// RawContent returns the undecoded content of the attachment
+	RawContent() json.RawMessage
+}
+
+type inbound struct {
+	typ string
+	raw json.RawMessage
+}
+
+// AttachmentType implements InboundAttachment
+func (i inbound) AttachmentType() string {
+	return i.typ
+}
+
+// RawContent implements InboundAttachment
+func (i inbound) RawContent() json.RawMessage {
+	return i.raw
+}
+
+// InboundText holds a text message sent by the user
+type InboundText struct {
+	inbound
+	Text string
+}
+
+// InboundPicture holds a picture sent by the user
+type InboundPicture struct {
+	inbound
+	URL string
+}
+
+// InboundVideo holds a video sent by the user
+type InboundVideo struct {
+	inbound
+	URL string
+}
+
+// InboundAudio holds an audio file sent by the user
+type InboundAudio struct {
+	inbound
+	URL string
+}
+
+// InboundFile holds a file sent by the user
+type InboundFile struct {
+	inbound
+	URL string
+}
+
+// InboundLocation holds a location shared by the user
+type InboundLocation struct {
+	inbound
+	Lat float64
+	Lng float64
+}
+
+// InboundPostback is sent when the user clicks on a postback button
+type InboundPostback struct {
+	inbound
+	Title string
+	Value string
+}
+
+// InboundQuickReplyAnswer is sent when the user chooses one of the quick replies
+type InboundQuickReplyAnswer struct {
+	inbound
+	Title string
+	Value string
+}
+
+// InboundComponent holds the structured messages sent by a bot, as found
+// in the messages of a connector conversation
+//	if c, ok := message.Content.(*recast.InboundComponent); ok {
+//		card, isCard := c.Component.(*recast.Card)
+//	}
+type InboundComponent struct {
+	inbound
+	Component Component
+}
+
+// InboundUnknown holds attachments whose type is not known by this package,
+// or whose content does not match their type
+// Their content is available through RawContent
+type InboundUnknown struct {
+	inbound
+}
+
+type rawInboundAttachment struct {
+	Type    string          `json:"type"`
+	Content json.RawMessage `json:"content"`
+}
+
+// parseInboundAttachment decodes the attachment of a connector message
+// according to its type, an error is only returned if data is not an attachment
+func parseInboundAttachment(data json.RawMessage) (InboundAttachment, error) {
+	var raw rawInboundAttachment
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw.Content) == 0 {
+		raw.Content = json.RawMessage("null")
+	}
+	base := inbound{typ: raw.Type, raw: raw.Content}
+
+	var a InboundAttachment
+	var err error
+	switch raw.Type {
+	case "text":
+		text := &InboundText{inbound: base}
+		a, err = text, decodeInboundString(raw.Content, &text.Text)
+	case "picture":
+		picture := &InboundPicture{inbound: base}
+		a, err = picture, decodeInboundURL(raw.Content, &picture.URL)
+	case "video":
+		video := &InboundVideo{inbound: base}
+		a, err = video, decodeInboundURL(raw.Content, &video.URL)
+	case "audio":
+		audio := &InboundAudio{inbound: base}
+		a, err = audio, decodeInboundURL(raw.Content, &audio.URL)
+	case "file":
+		file := &InboundFile{inbound: base}
+		a, err = file, decodeInboundURL(raw.Content, &file.URL)
+	case "location":
+		location := &InboundLocation{inbound: base}
+		a, err = location, decodeInboundLocation(raw.Content, location)
+	case "payload", "postback":
+		postback := &InboundPostback{inbound: base}
+		a, err = postback, decodeInboundTitleValue(raw.Content, &postback.Title, &postback.Value)
+	case "quickReply", "quick_reply":
+		answer := &InboundQuickReplyAnswer{inbound: base}
+		a, err = answer, decodeInboundTitleValue(raw.Content, &answer.Title, &answer.Value)
+	case "card", "carousel", "list", "buttons", "quickReplies":
+		var c Component
+		c, err = decodeComponent(data)
+		a = &InboundComponent{inbound: base, Component: c}
+	default:
+		a = &InboundUnknown{inbound: base}
+	}
+
+	// the content of a known type which cannot be decoded is kept as is
+	if err != nil {
+		return &InboundUnknown{inbound: base}, nil
+	}
+	return a, nil
+}
+
+func isJSONString(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '"'
+}
+
+func decodeInboundString(data json.RawMessage, s *string) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if !isJSONString(data) {
+		*s = string(data)
+		return nil
+	}
+	return json.Unmarshal(data, s)
+}
+
+func decodeInboundURL(data json.RawMessage, url *string) error {
+	if isJSONString(data) {
+		return json.Unmarshal(data, url)
+	}
+	var content struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("Invalid attachment url: %v", err)
+	}
+	*url = content.URL
+	return nil
+}
+
+func decodeInboundLocation(data json.RawMessage, l *InboundLocation) error {
+	var content struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+		Lat       *float64 `json:"lat"`
+		Lng       *float64 `json:"lng"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("Invalid location: %v", err)
+	}
+	if content.Latitude != nil {
+		l.Lat = *content.Latitude
+	} else if content.Lat != nil {
+		l.Lat = *content.Lat
+	}
+	if content.Longitude != nil {
+		l.Lng = *content.Longitude
+	} else if content.Lng != nil {
+		l.Lng = *content.Lng
+	}
+	return nil
+}
+
+func decodeInboundTitleValue(data json.RawMessage, title, value *string) error {
+	if isJSONString(data) {
+		return json.Unmarshal(data, value)
+	}
+	var content struct {
+		Title   string `json:"title"`
+		Value   string `json:"value"`
+		Payload string `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("Invalid postback: %v", err)
+	}
+	*title = content.Title
+	*value = content.Value
+	if *value == "" {
+		*value = content.Payload
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package recast
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInboundAttachmentParsing(t *testing.T) {
+	testCases := []struct {
+		payload string
+		check   func(a InboundAttachment) bool
+	}{
+		{`{"type":"text","content":"Hello"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundText)
+			return ok && v.Text == "Hello"
+		}},
+		{`{"type":"picture","content":"https://example.com/a.png"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundPicture)
+			return ok && v.URL == "https://example.com/a.png"
+		}},
+		{`{"type":"video","content":{"url":"https://example.com/a.mp4"}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundVideo)
+			return ok && v.URL == "https://example.com/a.mp4"
+		}},
+		{`{"type":"audio","content":"https://example.com/a.mp3"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundAudio)
+			return ok && v.URL == "https://example.com/a.mp3"
+		}},
+		{`{"type":"file","content":"https://example.com/a.pdf"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundFile)
+			return ok && v.URL == "https://example.com/a.pdf"
+		}},
+		{`{"type":"location","content":{"latitude":48.85,"longitude":2.35}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundLocation)
+			return ok && v.Lat == 48.85 && v.Lng == 2.35
+		}},
+		{`{"type":"payload","content":"SAY_HELLO"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundPostback)
+			return ok && v.Value == "SAY_HELLO"
+		}},
+		{`{"type":"postback","content":{"title":"Say hello","value":"SAY_HELLO"}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundPostback)
+			return ok && v.Title == "Say hello" && v.Value == "SAY_HELLO"
+		}},
+		{`{"type":"quickReply","content":{"title":"Yes","value":"yes"}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundQuickReplyAnswer)
+			return ok && v.Title == "Yes" && v.Value == "yes"
+		}},
+		{`{"type":"card","content":{"title":"Recast.AI","buttons":[{"title":"Call","type":"phonenumber","value":"+33600000000"}]}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundComponent)
+			if !ok {
+				return false
+			}
+			card, ok := v.Component.(*Card)
+			return ok && card.Content.Buttons[0].Kind() == ButtonPhone
+		}},
+		{`{"type":"sticker","content":{"id":42}}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundUnknown)
+			return ok && v.AttachmentType() == "sticker" && string(v.RawContent()) == `{"id":42}`
+		}},
+		{`{"type":"location","content":"somewhere"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundUnknown)
+			return ok && v.AttachmentType() == "location" && string(v.RawContent()) == `"somewhere"`
+		}},
+		{`{"type":"card","content":"not a card"}`, func(a InboundAttachment) bool {
+			v, ok := a.(*InboundUnknown)
+			return ok && v.AttachmentType() == "card" && string(v.RawContent()) == `"not a card"`
+		}},
+	}
+
+	for i, tc := range testCases {
+		a, err := parseInboundAttachment(json.RawMessage(tc.payload))
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v for test case:%d", err, i)
+		}
+		if !tc.check(a) {
+			t.Errorf("Invalid attachment %#v for test case:%d", a, i)
+		}
+	}
+}
+
+func TestMessageWithStructuredAttachment(t *testing.T) {
+	payload := `{
+		"conversation": "conversation_id",
+		"attachment": {"type": "location", "content": {"latitude": 1.5, "longitude": -3}}
+	}`
+
+	var msg Message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	if _, ok := msg.Content.(*InboundLocation); !ok {
+		t.Fatalf("Expected a location, but instead got %#v", msg.Content)
+	}
+	if msg.Attachment.Type != "location" || msg.Attachment.Content != `{"latitude": 1.5, "longitude": -3}` {
+		t.Errorf("Attachment should hold the raw content, got %+v", msg.Attachment)
+	}
+	if msg.ConversationID != "conversation_id" {
+		t.Errorf("Invalid conversation id: %s", msg.ConversationID)
+	}
+}
@@ -0,0 +1,122 @@
+package recast
+
+import (
+	"regexp"
+)
+
+// ButtonType is the kind of action triggered by a button
+type ButtonType string
+
+// Button types, they are set in CardButton.Type by the typed button constructors
+// The constructors do not check their arguments, CardButton.Validate does
+const (
+	// ButtonPostback sends its value back to the bot
+	ButtonPostback ButtonType = "postback"
+	// ButtonURL opens its value in a browser
+	ButtonURL ButtonType = "web_url"
+	// ButtonPhone calls the phone number in its value
+	ButtonPhone ButtonType = "phone_number"
+	// ButtonShare lets the user share the message
+	ButtonShare ButtonType = "element_share"
+	// ButtonLogin starts the account linking flow at the URL in its value
+	ButtonLogin ButtonType = "account_link"
+)
+
+// buttonAliases maps the type names used by the channels and older
+// versions of the connector to the button types
+var buttonAliases = map[ButtonType]ButtonType{
+	ButtonPostback:  ButtonPostback,
+	"payload":       ButtonPostback,
+	ButtonURL:       ButtonURL,
+	"url":           ButtonURL,
+	ButtonPhone:     ButtonPhone,
+	"phonenumber":   ButtonPhone,
+	"phone":         ButtonPhone,
+	ButtonShare:     ButtonShare,
+	"share":         ButtonShare,
+	ButtonLogin:     ButtonLogin,
+	"account_login": ButtonLogin,
+	"login":         ButtonLogin,
+}
+
+var phoneRegexp = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// PostbackButton returns a button sending payload back to the bot when clicked
+func PostbackButton(title, payload string) CardButton {
+	return CardButton{Title: title, Type: ButtonPostback, Value: payload}
+}
+
+// URLButton returns a button opening url when clicked
+func URLButton(title, url string) CardButton {
+	return CardButton{Title: title, Type: ButtonURL, Value: url}
+}
+
+// PhoneButton returns a button calling phone when clicked
+// The phone number must be in international format (+33612345678)
+func PhoneButton(title, phone string) CardButton {
+	return CardButton{Title: title, Type: ButtonPhone, Value: phone}
+}
+
+// ShareButton returns a button letting the user share the message
+func ShareButton(title string) CardButton {
+	return CardButton{Title: title, Type: ButtonShare}
+}
+
+// LoginButton returns a button starting the account linking flow at url
+func LoginButton(title, url string) CardButton {
+	return CardButton{Title: title, Type: ButtonLogin, Value: url}
+}
+
+// Kind returns the type of the button, recognizing the alternative type names
+// used by the channels, or an empty ButtonType if the type is unknown
+func (b CardButton) Kind() ButtonType {
+	return buttonAliases[b.Type]
+}
+
+// Validate checks that the button has a title and that its value matches its type:
+// a payload for postbacks, an absolute http(s) URL for URLs and logins
+// and an international phone number for phones
+//	if err := recast.URLButton("Visit", url).Validate(); err != nil {
+//		return err
+//	}
+func (b CardButton) Validate() error {
+	check := &limitChecker{component: "button"}
+	check.button(b)
+	return check.err
+}
+
+// AddButtons adds typed buttons to a Card
+//	card := recast.NewCard("Recast.AI", "").
+//		AddButtons(recast.URLButton("Visit", "https://recast.ai"), recast.ShareButton("Share"))
+func (c *Card) AddButtons(buttons ...CardButton) *Card {
+	c.Content.Buttons = append(c.Content.Buttons, buttons...)
+	return c
+}
+
+// AddButtons adds typed buttons to a CarouselCard
+func (c *CarouselCard) AddButtons(buttons ...CardButton) *CarouselCard {
+	c.Buttons = append(c.Buttons, buttons...)
+	return c
+}
+
+// AddButtons adds typed buttons to a Buttons message
+func (b *Buttons) AddButtons(buttons ...CardButton) *Buttons {
+	b.Content.Buttons = append(b.Content.Buttons, buttons...)
+	return b
+}
+
+// AddButtons adds typed buttons to a list element
+func (e *ListElement) AddButtons(buttons ...CardButton) *ListElement {
+	for _, b := range buttons {
+		e.Buttons = append(e.Buttons, ListButton(b))
+	}
+	return e
+}
+
+// AddButtons adds typed buttons to a list
+func (l *List) AddButtons(buttons ...CardButton) *List {
+	for _, b := range buttons {
+		l.Content.Buttons = append(l.Content.Buttons, ListButton(b))
+	}
+	return l
+}
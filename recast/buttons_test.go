@@ -0,0 +1,67 @@
+package recast
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTypedButtons(t *testing.T) {
+	card := NewCard("Recast.AI", "").AddButtons(
+		PostbackButton("Hello", "SAY_HELLO"),
+		URLButton("Visit", "https://recast.ai"),
+		PhoneButton("Call", "+33600000000"),
+	)
+	if err := card.Validate(); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	data, _ := json.Marshal(card.Content.Buttons[1])
+	if string(data) != `{"title":"Visit","type":"web_url","value":"https://recast.ai"}` {
+		t.Fatalf("Typed buttons should keep the connector format, got %s", data)
+	}
+
+	invalid := []CardButton{
+		PostbackButton("Hello", ""),
+		PostbackButton("", "SAY_HELLO"),
+		URLButton("Visit", "/relative/path"),
+		URLButton("Visit", "recast.ai"),
+		PhoneButton("Call", "0600000000"),
+		LoginButton("Log in", ""),
+		{Title: "Typo", Type: "postbak", Value: "value"},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); err == nil {
+			t.Errorf("Expected err not to be nil for %+v", b)
+		}
+	}
+	if err := ShareButton("Share").Validate(); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+}
+
+func TestButtonKind(t *testing.T) {
+	kinds := map[string]ButtonType{
+		"postback":      ButtonPostback,
+		"web_url":       ButtonURL,
+		"phonenumber":   ButtonPhone,
+		"phone_number":  ButtonPhone,
+		"element_share": ButtonShare,
+		"account_link":  ButtonLogin,
+		"unknown":       "",
+	}
+
+	body := []byte(`{"messages":[{"type":"buttons","content":{"title":"Choose","buttons":[{"title":"Call","type":"phonenumber","value":"+33600000000"}]}}],"conversation":{"id":"conversation_id"},"nlp":{}}`)
+	dialog, err := parseDialog(body)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if kind := dialog.Messages[0].(*Buttons).Content.Buttons[0].Kind(); kind != ButtonPhone {
+		t.Fatalf("Expected %s, but instead got %s", ButtonPhone, kind)
+	}
+
+	for typ, kind := range kinds {
+		if k := (CardButton{Type: ButtonType(typ)}).Kind(); k != kind {
+			t.Errorf("Expected %q for %s, but instead got %q", kind, typ, k)
+		}
+	}
+}
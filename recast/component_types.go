@@ -0,0 +1,153 @@
+package recast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RawComponent holds a message of a type unknown to the SDK
+// Its type and content are kept as is, so it can be inspected or sent back
+// It can also be used to send custom payloads to a channel
+//	payload := &recast.RawComponent{Type: "custom", Content: json.RawMessage(`{"foo":"bar"}`)}
+type RawComponent struct {
+	Type    string          `json:"type"`
+	Content json.RawMessage `json:"content"`
+}
+
+// IsComponent marks RawComponent as a valid messaging content
+func (c *RawComponent) IsComponent() bool {
+	return true
+}
+
+var componentTypes = struct {
+	sync.RWMutex
+	factories map[string]func() Component
+}{factories: map[string]func() Component{}}
+
+// RegisterComponentType registers the component decoded for messages of type typ
+// factory must return a pointer to a new component, the whole message
+// ({"type": ..., "content": ...}) is decoded into it
+// Registering a type that is already registered replaces its factory
+//	type Sticker struct {
+//		Type    string `json:"type"`
+//		Content struct {
+//			ID string `json:"id"`
+//		} `json:"content"`
+//	}
+//	func (s *Sticker) IsComponent() bool { return true }
+//
+//	recast.RegisterComponentType("sticker", func() recast.Component { return &Sticker{} })
+func RegisterComponentType(typ string, factory func() Component) {
+	componentTypes.Lock()
+	defer componentTypes.Unlock()
+	componentTypes.factories[typ] = factory
+}
+
+func init() {
+	for _, typ := range []string{"text", "picture", "video"} {
+		RegisterComponentType(typ, func() Component { return &Attachment{} })
+	}
+	RegisterComponentType("quickReplies", func() Component { return &QuickReplies{} })
+	RegisterComponentType("carousel", func() Component { return &Carousel{} })
+	RegisterComponentType("list", func() Component { return &List{} })
+	RegisterComponentType("card", func() Component { return &Card{} })
+	RegisterComponentType("buttons", func() Component { return &Buttons{} })
+	RegisterComponentType("audio", func() Component { return &Audio{} })
+	RegisterComponentType("file", func() Component { return &File{} })
+	RegisterComponentType("delay", func() Component { return &Delay{} })
+}
+
+// registeredComponentType returns the type c is registered for,
+// empty if it is not registered or registered for several types
+func registeredComponentType(c Component) string {
+	componentTypes.RLock()
+	defer componentTypes.RUnlock()
+	typ, found := "", reflect.TypeOf(c)
+	for name, factory := range componentTypes.factories {
+		if reflect.TypeOf(factory()) == found {
+			if typ != "" {
+				return ""
+			}
+			typ = name
+		}
+	}
+	return typ
+}
+
+// decodeComponent decodes a message with the factory registered for its type,
+// or into a RawComponent if the type is unknown
+func decodeComponent(data json.RawMessage) (Component, error) {
+	var raw RawComponent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	componentTypes.RLock()
+	factory, found := componentTypes.factories[raw.Type]
+	componentTypes.RUnlock()
+	if !found {
+		return &raw, nil
+	}
+
+	c := factory()
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("Invalid %s message: %v", raw.Type, err)
+	}
+	return c, nil
+}
+
+// ComponentList is a list of messages which can be decoded back from JSON
+// The messages are decoded according to the registered component types
+//	var messages recast.ComponentList
+//	err := json.Unmarshal(data, &messages)
+type ComponentList []Component
+
+// UnmarshalJSON decodes a JSON array of messages
+func (l *ComponentList) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*l = nil
+		return nil
+	}
+
+	list := make(ComponentList, 0, len(raw))
+	for _, message := range raw {
+		c, err := decodeComponent(message)
+		if err != nil {
+			return err
+		}
+		list = append(list, c)
+	}
+	*l = list
+	return nil
+}
+
+// MarshalJSON encodes the messages as a JSON array
+func (l ComponentList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]Component(l))
+}
+
+// ParseComponents decodes messages stored as JSON, either as an array
+// of messages or as an object with a "messages" array like the
+// dialog responses and the connector requests
+func ParseComponents(data []byte) ([]Component, error) {
+	var list ComponentList
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Messages ComponentList `json:"messages"`
+		}
+		err := json.Unmarshal(trimmed, &wrapper)
+		return wrapper.Messages, err
+	}
+	err := json.Unmarshal(data, &list)
+	return list, err
+}
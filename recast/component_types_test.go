@@ -0,0 +1,86 @@
+package recast
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testSticker struct {
+	Type    string `json:"type"`
+	Content struct {
+		ID string `json:"id"`
+	} `json:"content"`
+}
+
+func (s *testSticker) IsComponent() bool {
+	return true
+}
+
+func TestParseUnknownMessages(t *testing.T) {
+	body := []byte(`{"messages":[{"type":"text","content":"Hello"},{"type":"hologram","content":{"color":"blue"}}],"conversation":{"id":"conversation_id"},"nlp":{}}`)
+
+	dialog, err := parseDialog(body)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if text, ok := dialog.Messages[0].(*Attachment); !ok || text.Content != "Hello" {
+		t.Fatalf("Unexpected message: %+v", dialog.Messages[0])
+	}
+	raw, ok := dialog.Messages[1].(*RawComponent)
+	if !ok || raw.Type != "hologram" || string(raw.Content) != `{"color":"blue"}` {
+		t.Fatalf("Unknown messages should be kept as RawComponent: %+v", dialog.Messages[1])
+	}
+
+	data, _ := json.Marshal(raw)
+	if string(data) != `{"type":"hologram","content":{"color":"blue"}}` {
+		t.Fatalf("A RawComponent should be marshalled as received: %s", data)
+	}
+}
+
+func TestRegisterComponentType(t *testing.T) {
+	RegisterComponentType("sticker", func() Component { return &testSticker{} })
+
+	body := []byte(`{"messages":[{"type":"sticker","content":{"id":"369239263222822"}}],"conversation":{"id":"conversation_id"},"nlp":{}}`)
+	dialog, err := parseDialog(body)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if sticker, ok := dialog.Messages[0].(*testSticker); !ok || sticker.Content.ID != "369239263222822" {
+		t.Fatalf("Unexpected message: %+v", dialog.Messages[0])
+	}
+
+	body = []byte(`{"messages":[{"type":"sticker","content":"369239263222822"}],"conversation":{"id":"conversation_id"},"nlp":{}}`)
+	if _, err := parseDialog(body); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
+
+func TestComponentsRoundTrip(t *testing.T) {
+	messages := ComponentList(adapterTestMessages())
+	messages = append(messages, &RawComponent{Type: "hologram", Content: json.RawMessage(`{"color":"blue"}`)})
+
+	data, err := json.Marshal(messages)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	var decoded ComponentList
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	again, _ := json.Marshal(decoded)
+	if string(again) != string(data) {
+		t.Fatalf("Expected %s, but instead got %s", data, again)
+	}
+	if card, ok := decoded[3].(*Card); !ok || card.Content.Buttons[2].Kind() != ButtonPhone {
+		t.Fatalf("Unexpected card: %+v", decoded[3])
+	}
+
+	wrapped, err := ParseComponents([]byte(`{"messages":` + string(data) + `}`))
+	if err != nil || len(wrapped) != len(messages) {
+		t.Fatalf("Unexpected messages: %+v %+v", wrapped, err)
+	}
+	if _, err := ParseComponents([]byte(`[{"type":"card","content":"not a card"}]`)); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
@@ -1,5 +1,9 @@
 package recast
 
+import (
+	"time"
+)
+
 // Component interface is used as a marker for the connector message formats
 // All data structure that can be sent as a message has to implement this interface
 type Component interface {
@@ -57,7 +61,7 @@ func (c *CarouselCard) AddImage(image string) *CarouselCard {
 
 //AddButton add button to CarouselCard.Buttons
 func (c *CarouselCard) AddButton(title, typ, value string) *CarouselCard {
-	c.Buttons = append(c.Buttons, CardButton{title, typ, value})
+	c.Buttons = append(c.Buttons, CardButton{title, ButtonType(typ), value})
 	return c
 }
 
@@ -93,7 +97,7 @@ func (e *ListElement) AddImage(image string) *ListElement {
 func (e *ListElement) AddButton(title, typ, value string) *ListElement {
 	e.Buttons = append(e.Buttons, ListButton{
 		Title: title,
-		Type:  typ,
+		Type:  ButtonType(typ),
 		Value: value,
 	})
 	return e
@@ -133,7 +137,7 @@ func (l *List) AddElement(e *ListElement) *List {
 func (l *List) AddButton(title, typ, value string) *List {
 	l.Content.Buttons = append(l.Content.Buttons, ListButton{
 		Title: title,
-		Type:  typ,
+		Type:  ButtonType(typ),
 		Value: value,
 	})
 	return l
@@ -146,9 +150,9 @@ func (l *List) IsComponent() bool {
 
 // CardButton holds data for a button in messaging channels formats
 type CardButton struct {
-	Title string `json:"title"`
-	Type  string `json:"type"`
-	Value string `json:"value"`
+	Title string     `json:"title"`
+	Type  ButtonType `json:"type"`
+	Value string     `json:"value"`
 }
 
 // CardContent holds data for a card in messaging platforms
@@ -196,7 +200,7 @@ func (c *Card) AddImage(imageURL string) *Card {
 
 // AddButton adds a button with the specified title, type and value to a Card
 func (c *Card) AddButton(title, typ, value string) *Card {
-	c.Content.Buttons = append(c.Content.Buttons, CardButton{title, typ, value})
+	c.Content.Buttons = append(c.Content.Buttons, CardButton{title, ButtonType(typ), value})
 	return c
 }
 
@@ -268,3 +272,96 @@ func (c Attachment) IsComponent() bool {
 func NewTextMessage(text string) Attachment {
 	return Attachment{Type: "text", Content: text}
 }
+
+// ButtonsContent holds data for Buttons
+type ButtonsContent struct {
+	Title   string       `json:"title"`
+	Buttons []CardButton `json:"buttons"`
+}
+
+// Buttons holds format for a text message followed by actionable buttons
+//	buttons := recast.NewButtons("What do you want to do?").
+//		AddButton("Visit the website", "web_url", "https://recast.ai").
+//		AddButton("Talk to a human", "postback", "HUMAN")
+type Buttons struct {
+	Type    string         `json:"type"`
+	Content ButtonsContent `json:"content"`
+}
+
+// NewButtons initializes an empty Buttons message with the specified title
+func NewButtons(title string) *Buttons {
+	return &Buttons{
+		Type: "buttons",
+		Content: ButtonsContent{
+			Title:   title,
+			Buttons: []CardButton{},
+		},
+	}
+}
+
+// AddButton adds a button with the specified title, type and value
+func (b *Buttons) AddButton(title, typ, value string) *Buttons {
+	b.Content.Buttons = append(b.Content.Buttons, CardButton{title, ButtonType(typ), value})
+	return b
+}
+
+// IsComponent marks Buttons as a valid messaging content
+func (b *Buttons) IsComponent() bool {
+	return true
+}
+
+// Audio holds data for an audio message, Content is the URL of the audio file
+type Audio struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// NewAudio returns an audio message playing the file at url
+func NewAudio(url string) *Audio {
+	return &Audio{Type: "audio", Content: url}
+}
+
+// IsComponent marks Audio as a valid messaging content
+func (a *Audio) IsComponent() bool {
+	return true
+}
+
+// File holds data for a file message, Content is the URL of the file
+type File struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// NewFile returns a message to download the file at url
+func NewFile(url string) *File {
+	return &File{Type: "file", Content: url}
+}
+
+// IsComponent marks File as a valid messaging content
+func (f *File) IsComponent() bool {
+	return true
+}
+
+// Delay holds format for a pause between two messages
+// The typing indicator is displayed during the pause on the channels supporting it
+// Content is the duration of the pause in seconds
+//	err := client.SendMessage(conversationID, recast.NewTextMessage("Let me check..."), recast.NewDelay(2*time.Second), card)
+type Delay struct {
+	Type    string  `json:"type"`
+	Content float64 `json:"content"`
+}
+
+// NewDelay returns a pause of duration d
+func NewDelay(d time.Duration) *Delay {
+	return &Delay{Type: "delay", Content: d.Seconds()}
+}
+
+// Duration returns the duration of the pause
+func (d *Delay) Duration() time.Duration {
+	return time.Duration(d.Content * float64(time.Second))
+}
+
+// IsComponent marks Delay as a valid messaging content
+func (d *Delay) IsComponent() bool {
+	return true
+}
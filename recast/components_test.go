@@ -1,7 +1,11 @@
 package recast
 
 import (
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestComponentsInterface(t *testing.T) {
@@ -92,3 +96,41 @@ func TestAdditionalElementsCreation(t *testing.T) {
 		t.Fatal("NewTextMessage should return Type==text and Content==test")
 	}
 }
+
+func TestNewComponentsEncoding(t *testing.T) {
+	components := []Component{
+		NewButtons("What do you want to do?").AddButton("Visit", "web_url", "https://recast.ai"),
+		NewAudio("https://example.com/song.mp3"),
+		NewFile("https://example.com/doc.pdf"),
+		NewDelay(1500 * time.Millisecond),
+	}
+	expected := []string{
+		`{"type":"buttons","content":{"title":"What do you want to do?","buttons":[{"title":"Visit","type":"web_url","value":"https://recast.ai"}]}}`,
+		`{"type":"audio","content":"https://example.com/song.mp3"}`,
+		`{"type":"file","content":"https://example.com/doc.pdf"}`,
+		`{"type":"delay","content":1.5}`,
+	}
+
+	messages := make([]string, len(components))
+	for i, c := range components {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if string(data) != expected[i] {
+			t.Fatalf("Expected %s, but instead got %s", expected[i], data)
+		}
+		messages[i] = string(data)
+	}
+
+	decoded, err := ParseComponents([]byte("[" + strings.Join(messages, ",") + "]"))
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if !reflect.DeepEqual(decoded, components) {
+		t.Fatalf("Expected %+v, but instead got %+v", components, decoded)
+	}
+	if delay := decoded[3].(*Delay); delay.Duration() != 1500*time.Millisecond {
+		t.Fatalf("Unexpected delay: %s", delay.Duration())
+	}
+}
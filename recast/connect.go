@@ -4,16 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"time"
 
-	"github.com/parnurzeal/gorequest"
-	"os"
 	"strconv"
 )
 
 const (
-	conversationsEndpoint = "https://api.recast.ai/connect/v1/conversations/"
-	messagesEndpoint      = "https://api.recast.ai/connect/v1/messages/"
+	conversationsEndpoint = connectEndpoint + "conversations/"
+	messagesEndpoint      = connectEndpoint + "messages/"
 )
 
 var (
@@ -25,19 +25,104 @@ var (
 	ErrNoRequestConversationID = errors.New("The request's conversationID is empty")
 )
 
+// ExternalID holds an identifier issued by a messaging platform
+// Depending on the channel it is either a number or a string, both are accepted
+// when decoding a connector payload
+type ExternalID string
+
+// UnmarshalJSON implements json.Unmarshaler for numeric and string identifiers
+func (id *ExternalID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ExternalID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = ExternalID(n.String())
+	return nil
+}
+
+// Uint64 returns the numeric value of the identifier
+// It returns 0 when the platform does not use numeric identifiers
+func (id ExternalID) Uint64() uint64 {
+	n, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// String returns the identifier as sent by the platform
+func (id ExternalID) String() string {
+	return string(id)
+}
+
 // Message contains data sent by Recast.AI connector.
 type Message struct {
-	ConversationID string     `json:"conversation"`
-	Attachment     Attachment `json:"attachment"`
-	SenderID       uint64
-	ChatID         uint64
+	ID             string                 `json:"_id"`
+	ConversationID string                 `json:"conversation"`
+	Participant    string                 `json:"participant"`
+	Attachment     Attachment             `json:"attachment"`
+	ReceivedAt     time.Time              `json:"receivedAt"`
+	IsActive       bool                   `json:"isActive"`
+	Data           map[string]interface{} `json:"data"`
+	// Content holds the typed attachment, see InboundAttachment
+	Content InboundAttachment `json:"-"`
+	// ChannelType is the type of the channel the message comes from (slack, messenger, ...)
+	ChannelType string `json:"-"`
+	// Sender and Chat hold the platform identifiers of the sender and of the chat
+	Sender ExternalID `json:"-"`
+	Chat   ExternalID `json:"-"`
+	// Origin holds the raw payload sent by the connector
+	Origin json.RawMessage `json:"-"`
+	// SenderID and ChatID are only set for platforms using numeric identifiers,
+	// use Sender and Chat instead
+	SenderID uint64
+	ChatID   uint64
+}
+
+// UnmarshalJSON decodes a connector message and its attachment
+// Attachment.Content holds the raw JSON content when it is not a string
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message
+	aux := struct {
+		*message
+		Attachment json.RawMessage `json:"attachment"`
+	}{message: (*message)(m)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	content, err := parseInboundAttachment(aux.Attachment)
+	if err != nil {
+		return err
+	}
+	m.Content = content
+	m.Attachment = Attachment{}
+	if content != nil {
+		m.Attachment.Type = content.AttachmentType()
+		err = decodeInboundString(content.RawContent(), &m.Attachment.Content)
+	}
+	return err
 }
 
 // MessageData contains the Message and messaging informations about the message
 type MessageData struct {
-	Message  Message `json:"message"`
-	SenderID uint64  `json:"senderId"`
-	ChatID   uint64  `json:"chatId"`
+	Message     Message    `json:"message"`
+	SenderID    ExternalID `json:"senderId"`
+	ChatID      ExternalID `json:"chatId"`
+	ChannelType string     `json:"channelType"`
 }
 
 // ParseConnectorMessage handles a request coming from BotConnector API.
@@ -48,12 +133,24 @@ func ParseConnectorMessage(r *http.Request) (Message, error) {
 	}
 	defer r.Body.Close()
 
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(body) == 0 {
+		return Message{}, ErrNoRequestBody
+	}
+
 	var msg MessageData
-	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+	if err := json.Unmarshal(body, &msg); err != nil {
 		return Message{}, err
 	}
-	msg.Message.SenderID = msg.SenderID
-	msg.Message.ChatID = msg.ChatID
+	msg.Message.Sender = msg.SenderID
+	msg.Message.Chat = msg.ChatID
+	msg.Message.SenderID = msg.SenderID.Uint64()
+	msg.Message.ChatID = msg.ChatID.Uint64()
+	msg.Message.ChannelType = msg.ChannelType
+	msg.Message.Origin = json.RawMessage(body)
 
 	return msg.Message, nil
 }
@@ -79,8 +176,18 @@ func (f MessageHandlerFunc) ServeMessage(w MessageWriter, m Message) {
 // MessageWriter is the structure
 // allowing you to respond.
 type MessageWriter interface {
+	// Reply sends messages to the conversation the message comes from
 	Reply(messages ...Component) error
+	// ReplyText formats a text message and sends it to the conversation
+	ReplyText(format string, args ...interface{}) error
+	// ReplyAfter shows the typing indicator, waits for delay and sends messages to the conversation
+	ReplyAfter(delay time.Duration, messages ...Component) error
+	// SendTyping shows or hides the typing indicator in the conversation
+	SendTyping(on bool) error
+	// Broadcast sends messages to all the users of the bot
 	Broadcast(messages ...Component) error
+	// Context returns the conversation and the participant the message comes from
+	Context() *Context
 }
 
 // ConnectClient provides an interface to Recast.AI connector service
@@ -90,8 +197,20 @@ type MessageWriter interface {
 //	message := recast.NewTextMessage("Hello")
 //	err := client.SendMessage("CONVERSATION_ID", message)
 type ConnectClient struct {
-	Token   string
-	handler MessageHandler
+	Token string
+	// Endpoint overrides the location of the Recast.AI API (https://api.recast.ai/)
+	// It is mostly useful to run the client against a test server
+	Endpoint string
+	// HTTPClient sends the requests when set
+	HTTPClient *http.Client
+	// Transport is used to perform the requests when set and HTTPClient is not
+	// Otherwise requests go through the proxy set in RECAST_PROXY if any
+	Transport http.RoundTripper
+	// ValidateMessages makes the client check the messages before sending them
+	// Replies are checked against the limits of the conversation channel,
+	// other messages against the default limits
+	ValidateMessages bool
+	handler          MessageHandler
 }
 
 // NewConnectClient creates a new client with the provided
@@ -103,6 +222,14 @@ func NewConnectClient(token string) *ConnectClient {
 	}
 }
 
+func (client *ConnectClient) url(endpoint string) string {
+	return endpointURL(client.Endpoint, endpoint)
+}
+
+func (client *ConnectClient) httpClient() *http.Client {
+	return newHTTPClient(client.HTTPClient, client.Transport)
+}
+
 // SendMessage send messages to Recast.AI botconnector service
 // A message can either be a Card, a QuickReplies or an Attachment structure
 //	card := recast.NewCard("Hi!").
@@ -111,41 +238,29 @@ func NewConnectClient(token string) *ConnectClient {
 //		AddButton("Say goodbyes", "postback", "Goodbye")
 //	err := client.SendMessage("CONVERSATION_ID", card)
 func (client *ConnectClient) SendMessage(conversationID string, messages ...Component) error {
+	return client.sendMessage(conversationID, "", messages)
+}
+
+// sendMessage sends messages to a conversation of a channel of type channel
+func (client *ConnectClient) sendMessage(conversationID, channel string, messages []Component) error {
 	if len(messages) == 0 {
 		return ErrNoMessageToSend
 	}
 	if conversationID == "" {
 		return ErrNoRequestConversationID
 	}
-	httpClient := gorequest.New()
-	endpoint := conversationsEndpoint + conversationID + "/messages"
+	if client.ValidateMessages {
+		if err := ValidateMessages(channel, messages...); err != nil {
+			return err
+		}
+	}
+	endpoint := client.url(conversationsEndpoint + conversationID + "/messages")
 
 	send := struct {
 		Messages []Component `json:"messages"`
 	}{messages}
 
-	var response struct {
-		Message string `json:"message"`
-	}
-
-	resp, _, requestErr := httpClient.
-		Post(endpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", client.Token)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return requestErr[0]
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
-	}
-
-	return nil
+	return doRequest(client.httpClient(), http.MethodPost, endpoint, client.Token, send, nil, http.StatusCreated)
 }
 
 // BroadcastMessage sends messages to all users of a bot
@@ -157,34 +272,33 @@ func (client *ConnectClient) BroadcastMessage(messages ...Component) error {
 	if len(messages) == 0 {
 		return ErrNoMessageToSend
 	}
-	httpClient := gorequest.New()
+	if client.ValidateMessages {
+		if err := ValidateMessages("", messages...); err != nil {
+			return err
+		}
+	}
 
 	send := struct {
 		Messages []Component `json:"messages"`
 	}{messages}
 
-	var response struct {
-		Message string
-	}
-
-	resp, _, requestErr := httpClient.
-		Post(messagesEndpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", client.Token)).
-		EndStruct(&response)
+	return doRequest(client.httpClient(), http.MethodPost, client.url(messagesEndpoint), client.Token, send, nil, http.StatusCreated)
+}
 
-	if requestErr != nil {
-		return requestErr[0]
+// SendTyping shows or hides the typing indicator in a conversation
+// Channels which do not support typing indicators ignore it
+//	err := client.SendTyping("CONVERSATION_ID", true)
+func (client *ConnectClient) SendTyping(conversationID string, on bool) error {
+	if conversationID == "" {
+		return ErrNoRequestConversationID
 	}
+	endpoint := client.url(conversationsEndpoint + conversationID + "/typing")
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
-	}
+	send := struct {
+		Typing bool `json:"typing"`
+	}{on}
 
-	return nil
+	return doRequest(client.httpClient(), http.MethodPost, endpoint, client.Token, send, nil, http.StatusOK, http.StatusCreated)
 }
 
 // UseHandler specify the handler when message
@@ -195,11 +309,36 @@ func (client *ConnectClient) UseHandler(h MessageHandler) {
 
 type messageWriter struct {
 	client  *ConnectClient
-	Context *Context
+	context *Context
 }
 
 func (m *messageWriter) Reply(messages ...Component) error {
-	return m.client.SendMessage(m.Context.ConversationID, messages...)
+	return m.client.sendMessage(m.context.ConversationID, m.context.ChannelType, messages)
+}
+
+func (m *messageWriter) ReplyText(format string, args ...interface{}) error {
+	return m.Reply(NewTextMessage(fmt.Sprintf(format, args...)))
+}
+
+// ReplyAfter does not fail when the typing indicator cannot be shown,
+// only the error of the reply itself is returned
+func (m *messageWriter) ReplyAfter(delay time.Duration, messages ...Component) error {
+	if len(messages) == 0 {
+		return ErrNoMessageToSend
+	}
+	if delay > 0 {
+		m.SendTyping(true)
+		time.Sleep(delay)
+	}
+	return m.Reply(messages...)
+}
+
+func (m *messageWriter) SendTyping(on bool) error {
+	return m.client.SendTyping(m.context.ConversationID, on)
+}
+
+func (m *messageWriter) Context() *Context {
+	return m.context
 }
 
 func (m *messageWriter) Broadcast(messages ...Component) error {
@@ -214,8 +353,13 @@ func (client *ConnectClient) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	if client.handler != nil {
 		writer := &messageWriter{
-			client:  client,
-			Context: &Context{ConversationID: message.ConversationID, SenderID: strconv.FormatUint(message.SenderID, 10)},
+			client: client,
+			context: &Context{
+				ConversationID: message.ConversationID,
+				SenderID:       message.Sender.String(),
+				Participant:    message.Participant,
+				ChannelType:    message.ChannelType,
+			},
 		}
 		go client.handler.ServeMessage(writer, message)
 	}
@@ -0,0 +1,403 @@
+package recast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// ChannelMessenger is the type of Facebook Messenger channels
+	ChannelMessenger = "messenger"
+	// ChannelSlack is the type of Slack app channels
+	ChannelSlack = "slackapp"
+	// ChannelSlackWebhook is the type of the Slack channels set up with a webhook,
+	// it is also the channel type of the messages received from Slack
+	ChannelSlackWebhook = "slack"
+	// ChannelTelegram is the type of Telegram channels
+	ChannelTelegram = "telegram"
+	// ChannelKik is the type of Kik channels
+	ChannelKik = "kik"
+	// ChannelTwilio is the type of Twilio SMS channels
+	ChannelTwilio = "twilio"
+	// ChannelWebchat is the type of Recast.AI webchat channels
+	ChannelWebchat = "webchat"
+	// ChannelBotFramework is the type of Microsoft Bot Framework channels
+	ChannelBotFramework = "microsoft"
+)
+
+var (
+	// ErrNoConnectorID is returned when a channel request is made without a connector ID
+	ErrNoConnectorID = errors.New("The connector ID is empty")
+	// ErrNoChannelSlug is returned when a channel request is made without a channel slug
+	ErrNoChannelSlug = errors.New("The channel slug is empty")
+
+	slugRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// ChannelCredentials is implemented by the credentials of every channel type
+type ChannelCredentials interface {
+	// ChannelType returns the type of channel the credentials are used for
+	ChannelType() string
+	// Validate returns an error if a mandatory credential is missing
+	Validate() error
+}
+
+// MessengerCredentials holds the credentials of a Facebook Messenger channel
+type MessengerCredentials struct {
+	// PageToken is the page access token
+	PageToken string `json:"token"`
+	// AppSecret is the secret of the Facebook application
+	AppSecret string `json:"apiKey"`
+	// VerifyToken is the token used by Facebook to validate the webhook
+	VerifyToken string `json:"webhookToken,omitempty"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c MessengerCredentials) ChannelType() string {
+	return ChannelMessenger
+}
+
+// Validate implements ChannelCredentials
+func (c MessengerCredentials) Validate() error {
+	return requireCredentials(ChannelMessenger, "page token", c.PageToken, "app secret", c.AppSecret)
+}
+
+// SlackCredentials holds the credentials of a Slack app channel
+type SlackCredentials struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c SlackCredentials) ChannelType() string {
+	return ChannelSlack
+}
+
+// Validate implements ChannelCredentials
+func (c SlackCredentials) Validate() error {
+	return requireCredentials(ChannelSlack, "client id", c.ClientID, "client secret", c.ClientSecret)
+}
+
+// TelegramCredentials holds the credentials of a Telegram channel
+type TelegramCredentials struct {
+	// Token is the token given by the BotFather
+	Token string `json:"token"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c TelegramCredentials) ChannelType() string {
+	return ChannelTelegram
+}
+
+// Validate implements ChannelCredentials
+func (c TelegramCredentials) Validate() error {
+	return requireCredentials(ChannelTelegram, "token", c.Token)
+}
+
+// KikCredentials holds the credentials of a Kik channel
+type KikCredentials struct {
+	UserName string `json:"userName"`
+	APIKey   string `json:"apiKey"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c KikCredentials) ChannelType() string {
+	return ChannelKik
+}
+
+// Validate implements ChannelCredentials
+func (c KikCredentials) Validate() error {
+	return requireCredentials(ChannelKik, "user name", c.UserName, "api key", c.APIKey)
+}
+
+// TwilioCredentials holds the credentials of a Twilio channel
+type TwilioCredentials struct {
+	// AccountSID and AuthToken identify the Twilio account
+	AccountSID string `json:"clientId"`
+	AuthToken  string `json:"clientSecret"`
+	// ServiceID is the messaging service used to send messages
+	ServiceID string `json:"serviceId"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c TwilioCredentials) ChannelType() string {
+	return ChannelTwilio
+}
+
+// Validate implements ChannelCredentials
+func (c TwilioCredentials) Validate() error {
+	return requireCredentials(ChannelTwilio, "account sid", c.AccountSID, "auth token", c.AuthToken, "service id", c.ServiceID)
+}
+
+// WebchatCredentials holds the settings of a Recast.AI webchat channel
+// The webchat does not need any credential
+type WebchatCredentials struct {
+	AccentColor    string `json:"accentColor,omitempty"`
+	WelcomeMessage string `json:"welcomeMessage,omitempty"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c WebchatCredentials) ChannelType() string {
+	return ChannelWebchat
+}
+
+// Validate implements ChannelCredentials
+func (c WebchatCredentials) Validate() error {
+	return nil
+}
+
+// SlackWebhookCredentials holds the credentials of a Slack channel set up with a webhook
+type SlackWebhookCredentials struct {
+	// Token is the token of the Slack bot user
+	Token string `json:"token"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c SlackWebhookCredentials) ChannelType() string {
+	return ChannelSlackWebhook
+}
+
+// Validate implements ChannelCredentials
+func (c SlackWebhookCredentials) Validate() error {
+	return requireCredentials(ChannelSlackWebhook, "token", c.Token)
+}
+
+// BotFrameworkCredentials holds the credentials of a Microsoft Bot Framework channel
+type BotFrameworkCredentials struct {
+	// AppID and AppPassword identify the bot registered on the Bot Framework
+	AppID       string `json:"clientId"`
+	AppPassword string `json:"clientSecret"`
+}
+
+// ChannelType implements ChannelCredentials
+func (c BotFrameworkCredentials) ChannelType() string {
+	return ChannelBotFramework
+}
+
+// Validate implements ChannelCredentials
+func (c BotFrameworkCredentials) Validate() error {
+	return requireCredentials(ChannelBotFramework, "app id", c.AppID, "app password", c.AppPassword)
+}
+
+// requireCredentials takes pairs of credential names and values
+// and returns an error for the first empty value
+func requireCredentials(channelType string, pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			return fmt.Errorf("Invalid %s channel: %s is empty", channelType, pairs[i])
+		}
+	}
+	return nil
+}
+
+// Channel is a messaging platform connected to a bot through the Recast.AI connector
+//	channel := recast.Channel{
+//		Slug:        "my-telegram",
+//		IsActivated: true,
+//		Credentials: recast.TelegramCredentials{Token: "TELEGRAM_TOKEN"},
+//	}
+//	channel, err := client.CreateChannel("CONNECTOR_ID", channel)
+type Channel struct {
+	ID          string
+	Slug        string
+	Type        string
+	IsActivated bool
+	// Webhook is the URL to set on the messaging platform, it is returned by the API
+	Webhook     string
+	Credentials ChannelCredentials
+}
+
+type channelBase struct {
+	ID          string `json:"id,omitempty"`
+	Slug        string `json:"slug"`
+	Type        string `json:"type"`
+	IsActivated bool   `json:"isActivated"`
+	Webhook     string `json:"webhook,omitempty"`
+}
+
+// Validate checks the channel slug and credentials before it is sent to the API
+func (c Channel) Validate() error {
+	if err := checkSlug(c.Slug); err != nil {
+		return err
+	}
+	if c.Credentials == nil {
+		return fmt.Errorf("Invalid channel %s: credentials are missing", c.Slug)
+	}
+	if c.Type != "" && c.Type != c.Credentials.ChannelType() {
+		return fmt.Errorf("Invalid channel %s: %s credentials used for a %s channel", c.Slug, c.Credentials.ChannelType(), c.Type)
+	}
+	return c.Credentials.Validate()
+}
+
+// checkSlug returns an error if slug cannot identify a channel
+func checkSlug(slug string) error {
+	if slug == "" {
+		return ErrNoChannelSlug
+	}
+	if !slugRegexp.MatchString(slug) {
+		return fmt.Errorf("Invalid channel slug: %s", slug)
+	}
+	return nil
+}
+
+// MarshalJSON flattens the credentials into the channel as expected by the API
+func (c Channel) MarshalJSON() ([]byte, error) {
+	base := channelBase{c.ID, c.Slug, c.Type, c.IsActivated, c.Webhook}
+	if c.Credentials != nil {
+		base.Type = c.Credentials.ChannelType()
+	}
+
+	fields := map[string]interface{}{}
+	if c.Credentials != nil {
+		if err := mergeJSONFields(fields, c.Credentials); err != nil {
+			return nil, err
+		}
+	}
+	if err := mergeJSONFields(fields, base); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func mergeJSONFields(fields map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &fields)
+}
+
+// UnmarshalJSON decodes the channel and its credentials according to its type
+func (c *Channel) UnmarshalJSON(data []byte) error {
+	var base channelBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return err
+	}
+	*c = Channel{
+		ID:          base.ID,
+		Slug:        base.Slug,
+		Type:        base.Type,
+		IsActivated: base.IsActivated,
+		Webhook:     base.Webhook,
+	}
+
+	var err error
+	switch base.Type {
+	case ChannelMessenger:
+		var creds MessengerCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelSlack:
+		var creds SlackCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelSlackWebhook:
+		var creds SlackWebhookCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelTelegram:
+		var creds TelegramCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelKik:
+		var creds KikCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelTwilio:
+		var creds TwilioCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelWebchat:
+		var creds WebchatCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	case ChannelBotFramework:
+		var creds BotFrameworkCredentials
+		err = json.Unmarshal(data, &creds)
+		c.Credentials = creds
+	}
+	return err
+}
+
+func (client *ConnectClient) channelsURL(connectorID string) string {
+	return client.url(connectEndpoint + "connectors/" + connectorID + "/channels")
+}
+
+// ListChannels fetches all the channels of a connector
+func (client *ConnectClient) ListChannels(connectorID string) ([]Channel, error) {
+	if connectorID == "" {
+		return nil, ErrNoConnectorID
+	}
+
+	var channels []Channel
+	if err := doRequest(client.httpClient(), http.MethodGet, client.channelsURL(connectorID), client.Token, nil, &channels, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// GetChannel fetches a channel of a connector by its slug
+func (client *ConnectClient) GetChannel(connectorID, slug string) (Channel, error) {
+	if connectorID == "" {
+		return Channel{}, ErrNoConnectorID
+	}
+	if err := checkSlug(slug); err != nil {
+		return Channel{}, err
+	}
+
+	var channel Channel
+	if err := doRequest(client.httpClient(), http.MethodGet, client.channelsURL(connectorID)+"/"+slug, client.Token, nil, &channel, http.StatusOK); err != nil {
+		return Channel{}, err
+	}
+	return channel, nil
+}
+
+// CreateChannel validates and creates a channel for a connector
+// The returned channel holds the webhook to set on the messaging platform
+func (client *ConnectClient) CreateChannel(connectorID string, channel Channel) (Channel, error) {
+	if connectorID == "" {
+		return Channel{}, ErrNoConnectorID
+	}
+	if err := channel.Validate(); err != nil {
+		return Channel{}, err
+	}
+
+	var created Channel
+	if err := doRequest(client.httpClient(), http.MethodPost, client.channelsURL(connectorID), client.Token, channel, &created, http.StatusCreated); err != nil {
+		return Channel{}, err
+	}
+	return created, nil
+}
+
+// UpdateChannel validates and replaces the channel identified by slug
+func (client *ConnectClient) UpdateChannel(connectorID, slug string, channel Channel) (Channel, error) {
+	if connectorID == "" {
+		return Channel{}, ErrNoConnectorID
+	}
+	if err := checkSlug(slug); err != nil {
+		return Channel{}, err
+	}
+	if err := channel.Validate(); err != nil {
+		return Channel{}, err
+	}
+
+	var updated Channel
+	if err := doRequest(client.httpClient(), http.MethodPut, client.channelsURL(connectorID)+"/"+slug, client.Token, channel, &updated, http.StatusOK); err != nil {
+		return Channel{}, err
+	}
+	return updated, nil
+}
+
+// DeleteChannel deletes the channel identified by slug
+func (client *ConnectClient) DeleteChannel(connectorID, slug string) error {
+	if connectorID == "" {
+		return ErrNoConnectorID
+	}
+	if err := checkSlug(slug); err != nil {
+		return err
+	}
+	return doRequest(client.httpClient(), http.MethodDelete, client.channelsURL(connectorID)+"/"+slug, client.Token, nil, nil, http.StatusOK, http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChannelValidation(t *testing.T) {
+	testCases := []struct {
+		channel Channel
+		valid   bool
+	}{
+		{Channel{Slug: "telegram", Credentials: TelegramCredentials{Token: "token"}}, true},
+		{Channel{Slug: "telegram", Credentials: TelegramCredentials{}}, false},
+		{Channel{Slug: "", Credentials: TelegramCredentials{Token: "token"}}, false},
+		{Channel{Slug: "bad slug", Credentials: TelegramCredentials{Token: "token"}}, false},
+		{Channel{Slug: "messenger"}, false},
+		{Channel{Slug: "messenger", Type: ChannelSlack, Credentials: MessengerCredentials{PageToken: "t", AppSecret: "s"}}, false},
+		{Channel{Slug: "messenger", Credentials: MessengerCredentials{PageToken: "t", AppSecret: "s"}}, true},
+		{Channel{Slug: "slack", Credentials: SlackCredentials{ClientID: "id"}}, false},
+		{Channel{Slug: "kik", Credentials: KikCredentials{UserName: "bot", APIKey: "key"}}, true},
+		{Channel{Slug: "twilio", Credentials: TwilioCredentials{AccountSID: "sid", AuthToken: "token"}}, false},
+		{Channel{Slug: "webchat", Credentials: WebchatCredentials{}}, true},
+		{Channel{Slug: "slack-webhook", Credentials: SlackWebhookCredentials{Token: "token"}}, true},
+		{Channel{Slug: "slack-webhook", Credentials: SlackWebhookCredentials{}}, false},
+		{Channel{Slug: "microsoft", Credentials: BotFrameworkCredentials{AppID: "id", AppPassword: "password"}}, true},
+		{Channel{Slug: "microsoft", Credentials: BotFrameworkCredentials{AppID: "id"}}, false},
+	}
+
+	for i, tc := range testCases {
+		err := tc.channel.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("Expected err to be nil, but instead got %+v for test case:%d", err, i)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected err not to be nil, but instead got nil for test case:%d", i)
+		}
+	}
+}
+
+func TestChannelJSON(t *testing.T) {
+	channel := Channel{
+		Slug:        "messenger",
+		IsActivated: true,
+		Credentials: MessengerCredentials{PageToken: "page_token", AppSecret: "app_secret"},
+	}
+
+	data, err := json.Marshal(channel)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	if fields["type"] != ChannelMessenger || fields["token"] != "page_token" || fields["apiKey"] != "app_secret" {
+		t.Fatalf("Credentials should be flattened in the channel: %s", data)
+	}
+
+	var decoded Channel
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	creds, ok := decoded.Credentials.(MessengerCredentials)
+	if !ok || creds.PageToken != "page_token" || decoded.Slug != "messenger" || !decoded.IsActivated {
+		t.Fatalf("Unexpected decoded channel: %+v", decoded)
+	}
+}
+
+func TestChannelJSONRoundTrip(t *testing.T) {
+	channels := []Channel{
+		{Slug: "slack-webhook", Credentials: SlackWebhookCredentials{Token: "token"}},
+		{Slug: "microsoft", Credentials: BotFrameworkCredentials{AppID: "id", AppPassword: "password"}},
+	}
+
+	for _, channel := range channels {
+		data, err := json.Marshal(channel)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		var decoded Channel
+		if err = json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if decoded.Credentials != channel.Credentials {
+			t.Fatalf("Expected credentials %+v, but instead got %+v", channel.Credentials, decoded.Credentials)
+		}
+		if err = decoded.Validate(); err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+	}
+}
+
+func TestChannelsAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connect/v1/connectors/connector_id/channels", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"results":[{"slug":"telegram","type":"telegram","token":"token","isActivated":true}],"message":"Channels rendered with success"}`)
+		case "POST":
+			body, _ := ioutil.ReadAll(r.Body)
+			var channel map[string]interface{}
+			json.Unmarshal(body, &channel)
+			channel["id"] = "channel_id"
+			channel["webhook"] = "https://api.recast.ai/connect/v1/webhook/channel_id"
+			results, _ := json.Marshal(channel)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"results":%s,"message":"Channel successfully created"}`, results)
+		}
+	})
+	mux.HandleFunc("/connect/v1/connectors/connector_id/channels/telegram", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET", "PUT":
+			fmt.Fprint(w, `{"results":{"slug":"telegram","type":"telegram","token":"new_token"},"message":"Channel rendered with success"}`)
+		case "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	channels, err := client.ListChannels("connector_id")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(channels) != 1 || channels[0].Credentials.(TelegramCredentials).Token != "token" {
+		t.Fatalf("Unexpected channels: %+v", channels)
+	}
+
+	created, err := client.CreateChannel("connector_id", Channel{Slug: "slack", Credentials: SlackCredentials{ClientID: "id", ClientSecret: "secret"}})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if created.ID != "channel_id" || created.Webhook == "" || created.Credentials.(SlackCredentials).ClientSecret != "secret" {
+		t.Fatalf("Unexpected created channel: %+v", created)
+	}
+
+	if _, err = client.CreateChannel("connector_id", Channel{Slug: "slack", Credentials: SlackCredentials{}}); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if _, err = client.CreateChannel("", created); err != ErrNoConnectorID {
+		t.Fatalf("Expected ErrNoConnectorID, but instead got %+v", err)
+	}
+
+	channel, err := client.GetChannel("connector_id", "telegram")
+	if err != nil || channel.Slug != "telegram" {
+		t.Fatalf("Unexpected channel %+v (err: %+v)", channel, err)
+	}
+
+	updated, err := client.UpdateChannel("connector_id", "telegram", Channel{Slug: "telegram", Credentials: TelegramCredentials{Token: "new_token"}})
+	if err != nil || updated.Credentials.(TelegramCredentials).Token != "new_token" {
+		t.Fatalf("Unexpected channel %+v (err: %+v)", updated, err)
+	}
+
+	if err = client.DeleteChannel("connector_id", "telegram"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err = client.DeleteChannel("connector_id", ""); err != ErrNoChannelSlug {
+		t.Fatalf("Expected ErrNoChannelSlug, but instead got %+v", err)
+	}
+	for _, slug := range []string{"../connectors", "telegram?force=true", "tele gram"} {
+		if _, err = client.GetChannel("connector_id", slug); err == nil {
+			t.Fatalf("Expected err not to be nil for slug %q, but instead got nil", slug)
+		}
+		if _, err = client.UpdateChannel("connector_id", slug, updated); err == nil {
+			t.Fatalf("Expected err not to be nil for slug %q, but instead got nil", slug)
+		}
+		if err = client.DeleteChannel("connector_id", slug); err == nil {
+			t.Fatalf("Expected err not to be nil for slug %q, but instead got nil", slug)
+		}
+	}
+}
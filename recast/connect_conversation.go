@@ -0,0 +1,134 @@
+package recast
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Participant holds the informations about a participant of a connector conversation
+type Participant struct {
+	ID       string                 `json:"id"`
+	IsBot    bool                   `json:"isBot"`
+	SenderID ExternalID             `json:"senderId"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// ConnectorConversation is a conversation between a bot and the users of a channel
+// as stored by the Recast.AI connector
+type ConnectorConversation struct {
+	ID           string        `json:"id"`
+	Channel      string        `json:"channel"`
+	Connector    string        `json:"connector"`
+	ChatID       ExternalID    `json:"chatId"`
+	IsActive     bool          `json:"isActive"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	Participants []Participant `json:"participants"`
+	Messages     []Message     `json:"messages"`
+}
+
+// ListOpts contains the pagination options of the listing methods
+// Page starts at 1, the API defaults are used for zero values
+type ListOpts struct {
+	Page    int
+	PerPage int
+}
+
+func (opts *ListOpts) query() string {
+	if opts == nil {
+		return ""
+	}
+	values := url.Values{}
+	if opts.Page > 0 {
+		values.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage > 0 {
+		values.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// ConversationPage holds one page of connector conversations
+type ConversationPage struct {
+	Conversations []ConnectorConversation
+	Page          int
+	PerPage       int
+}
+
+// HasNext returns whether or not more conversations can be fetched with NextPage
+func (p ConversationPage) HasNext() bool {
+	return p.PerPage > 0 && len(p.Conversations) == p.PerPage
+}
+
+// NextPage returns the options to fetch the following page
+func (p ConversationPage) NextPage() *ListOpts {
+	return &ListOpts{Page: p.Page + 1, PerPage: p.PerPage}
+}
+
+// GetConversation fetches a conversation with its participants and messages
+//	conversation, err := client.GetConversation("CONVERSATION_ID")
+func (client *ConnectClient) GetConversation(conversationID string) (ConnectorConversation, error) {
+	if conversationID == "" {
+		return ConnectorConversation{}, ErrNoRequestConversationID
+	}
+
+	var conversation ConnectorConversation
+	err := doRequest(client.httpClient(), http.MethodGet, client.url(conversationsEndpoint+conversationID), client.Token, nil, &conversation, http.StatusOK)
+	if err != nil {
+		return ConnectorConversation{}, err
+	}
+	return conversation, nil
+}
+
+// ListConversations fetches the conversations of the bot, page by page
+// Set opts to nil to use the API pagination defaults
+//	opts := &recast.ListOpts{PerPage: 50}
+//	for {
+//		page, err := client.ListConversations(opts)
+//		if err != nil || !page.HasNext() {
+//			break
+//		}
+//		opts = page.NextPage()
+//	}
+func (client *ConnectClient) ListConversations(opts *ListOpts) (ConversationPage, error) {
+	page := ConversationPage{Page: 1}
+	if opts != nil {
+		if opts.Page > 0 {
+			page.Page = opts.Page
+		}
+		page.PerPage = opts.PerPage
+	}
+
+	endpoint := client.url(conversationsEndpoint) + opts.query()
+	err := doRequest(client.httpClient(), http.MethodGet, endpoint, client.Token, nil, &page.Conversations, http.StatusOK)
+	if err != nil {
+		return ConversationPage{}, err
+	}
+	return page, nil
+}
+
+// DeleteConversation deletes a conversation and all its messages
+func (client *ConnectClient) DeleteConversation(conversationID string) error {
+	if conversationID == "" {
+		return ErrNoRequestConversationID
+	}
+	return doRequest(client.httpClient(), http.MethodDelete, client.url(conversationsEndpoint+conversationID), client.Token, nil, nil, http.StatusOK, http.StatusNoContent)
+}
+
+// GetParticipants fetches the participants of a conversation
+func (client *ConnectClient) GetParticipants(conversationID string) ([]Participant, error) {
+	if conversationID == "" {
+		return nil, ErrNoRequestConversationID
+	}
+
+	var participants []Participant
+	endpoint := client.url(conversationsEndpoint + conversationID + "/participants")
+	if err := doRequest(client.httpClient(), http.MethodGet, endpoint, client.Token, nil, &participants, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
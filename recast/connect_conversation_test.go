@@ -0,0 +1,123 @@
+package recast
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newFakeConnector() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connect/v1/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token recast_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"results":null,"message":"Invalid token"}`)
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/connect/v1/conversations/":
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `{"results":[],"message":"Conversations rendered with success"}`)
+				return
+			}
+			fmt.Fprint(w, `{"results":[{"id":"c1","channel":"ch1"},{"id":"c2","channel":"ch1"}],"message":"Conversations rendered with success"}`)
+		case r.Method == "GET" && r.URL.Path == "/connect/v1/conversations/c1":
+			fmt.Fprint(w, getConnectorConversationJSONResponse())
+		case r.Method == "GET" && r.URL.Path == "/connect/v1/conversations/c1/participants":
+			fmt.Fprint(w, `{"results":[{"id":"p1","isBot":true},{"id":"p2","isBot":false,"senderId":"U42"}],"message":"Participants rendered with success"}`)
+		case r.Method == "DELETE" && r.URL.Path == "/connect/v1/conversations/c1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"results":null,"message":"Conversation not found"}`)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGetConversation(t *testing.T) {
+	server := newFakeConnector()
+	defer server.Close()
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	conversation, err := client.GetConversation("c1")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if conversation.ID != "c1" || len(conversation.Participants) != 2 || len(conversation.Messages) != 1 {
+		t.Fatalf("Unexpected conversation: %+v", conversation)
+	}
+	if conversation.Messages[0].Attachment.Content != "Hello" {
+		t.Errorf("Unexpected message: %+v", conversation.Messages[0])
+	}
+
+	if _, err = client.GetConversation("unknown"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if _, err = client.GetConversation(""); err != ErrNoRequestConversationID {
+		t.Fatalf("Expected ErrNoRequestConversationID, but instead got %+v", err)
+	}
+
+	client.Token = "bad_token"
+	if _, err = client.GetConversation("c1"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
+
+func TestListConversations(t *testing.T) {
+	server := newFakeConnector()
+	defer server.Close()
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	page, err := client.ListConversations(&ListOpts{PerPage: 2})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(page.Conversations) != 2 || page.Page != 1 || !page.HasNext() {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+
+	page, err = client.ListConversations(page.NextPage())
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(page.Conversations) != 0 || page.Page != 2 || page.HasNext() {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+
+	if _, err = client.ListConversations(nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+}
+
+func TestDeleteConversation(t *testing.T) {
+	server := newFakeConnector()
+	defer server.Close()
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	if err := client.DeleteConversation("c1"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := client.DeleteConversation("unknown"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
+
+func TestGetParticipants(t *testing.T) {
+	server := newFakeConnector()
+	defer server.Close()
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	participants, err := client.GetParticipants("c1")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(participants) != 2 || !participants[0].IsBot || participants[1].SenderID != "U42" {
+		t.Fatalf("Unexpected participants: %+v", participants)
+	}
+}
@@ -3,11 +3,13 @@ package recast
 import (
 	"github.com/jarcoal/httpmock"
 	"github.com/parnurzeal/gorequest"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestSendMessageParameters(t *testing.T) {
@@ -183,6 +185,47 @@ func TestParseMessage(t *testing.T) {
 	if msg.ConversationID != "f206b482-cb0c-435b-91bc-4628c8829d83" {
 		t.Error("Invalid conversation id")
 	}
+	if msg.ID != "61a7921b-f771-4211-82ca-05885160fd6d" {
+		t.Errorf("Invalid message id: %s", msg.ID)
+	}
+	if msg.Participant != "c9244b31-45f2-431c-be10-d3361851cf7e" {
+		t.Errorf("Invalid participant: %s", msg.Participant)
+	}
+	if msg.SenderID != 123467 || msg.Sender != "123467" {
+		t.Errorf("Invalid sender: %d %s", msg.SenderID, msg.Sender)
+	}
+	if msg.ChatID != 123456 || msg.Chat != "123456" {
+		t.Errorf("Invalid chat: %d %s", msg.ChatID, msg.Chat)
+	}
+	if msg.ReceivedAt.IsZero() {
+		t.Error("ReceivedAt should be set")
+	}
+	if len(msg.Origin) == 0 {
+		t.Error("Origin should hold the raw payload")
+	}
+}
+
+func TestParseMessageWithStringIDs(t *testing.T) {
+	reader := strings.NewReader(getValidSlackConversationMessage())
+	r, _ := http.NewRequest("POST", "/endpoint", reader)
+
+	msg, err := ParseConnectorMessage(r)
+	if err != nil {
+		t.Fatalf("Payload should be considered as valid: %+v", err)
+	}
+
+	if msg.Sender != "U2147483697" || msg.Chat != "D4F6A0B3K" {
+		t.Errorf("Invalid sender or chat: %s %s", msg.Sender, msg.Chat)
+	}
+	if msg.SenderID != 0 || msg.ChatID != 0 {
+		t.Errorf("Numeric ids should be empty for string identifiers")
+	}
+	if msg.ChannelType != "slack" {
+		t.Errorf("Invalid channel type: %s", msg.ChannelType)
+	}
+	if msg.Attachment.Content != "Hi there" {
+		t.Errorf("Invalid attachment content: %s", msg.Attachment.Content)
+	}
 }
 
 func TestHttpHandler(t *testing.T) {
@@ -221,3 +264,58 @@ func TestHttpHandler(t *testing.T) {
 		t.Errorf("The handler should have been called")
 	}
 }
+
+func TestMessageWriterHelpers(t *testing.T) {
+	client := NewConnectClient("recast_token")
+	conversationID := "conversation_id"
+	writer := &messageWriter{
+		client:  client,
+		context: &Context{ConversationID: conversationID, SenderID: "sender", Participant: "participant"},
+	}
+
+	gorequest.DisableTransportSwap = true
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var bodies []string
+	httpmock.RegisterResponder("POST", conversationsEndpoint+conversationID+"/messages", func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		return httpmock.NewStringResponse(http.StatusCreated, getSuccessfulPostMessageResponse()), nil
+	})
+	typing := 0
+	httpmock.RegisterResponder("POST", conversationsEndpoint+conversationID+"/typing", func(req *http.Request) (*http.Response, error) {
+		typing++
+		return httpmock.NewStringResponse(http.StatusOK, getSuccessfulPostMessageResponse()), nil
+	})
+
+	if err := writer.ReplyText("Hello %s", "World"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"content":"Hello World"`) {
+		t.Fatalf("Unexpected messages sent: %v", bodies)
+	}
+
+	start := time.Now()
+	if err := writer.ReplyAfter(10*time.Millisecond, NewTextMessage("Later")); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Errorf("ReplyAfter should wait before replying")
+	}
+	if typing != 1 || len(bodies) != 2 {
+		t.Errorf("ReplyAfter should send the typing indicator and the reply")
+	}
+	if err := writer.ReplyAfter(0); err != ErrNoMessageToSend {
+		t.Errorf("Expected ErrNoMessageToSend, but instead got %+v", err)
+	}
+
+	if err := writer.SendTyping(false); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	ctx := writer.Context()
+	if ctx.ConversationID != conversationID || ctx.Participant != "participant" {
+		t.Errorf("Unexpected context: %+v", ctx)
+	}
+}
@@ -1,9 +1,11 @@
 package recast
 
 const (
+	apiEndpoint     = "https://api.recast.ai/"
+	connectEndpoint = "https://api.recast.ai/connect/v1/"
+	buildEndpoint   = "https://api.recast.ai/build/v1/"
 	//@TODO uncomment this const when this API will be implements
 	//trainEndpoint    = "https://api.recast.ai/v2/"
-	//connectEndpoint  = "https://api.recast.ai/connect/v1/"
 	//hostEndpoint     = "https://api.recast.ai/host/v1/"
 	//monitorEndpoint  = "https://api.recast.ai/monitor/v1/"
 	requestEndpoint  = "https://api.recast.ai/v2/request/"
@@ -5,4 +5,6 @@ package recast
 type Context struct {
 	ConversationID string
 	SenderID       string
+	Participant    string
+	ChannelType    string
 }
@@ -1,12 +1,10 @@
 package recast
 
 import (
-	"fmt"
+	"context"
 	"net/http"
+	"net/url"
 	"time"
-
-	"github.com/parnurzeal/gorequest"
-	"os"
 )
 
 // Action represents a conversation action
@@ -35,11 +33,24 @@ type Conversation struct {
 	Status             int                    `json:"status"`
 	AuthorizationToken string
 	CustomEntities     map[string][]CustomEntity
+	// History holds the turns of the conversation since it was started with ConverseText
+	History []ConversationTurn `json:"-"`
+
+	client *RequestClient
+}
+
+// ConversationTurn is a sentence sent to the converse endpoint and the bot answer
+type ConversationTurn struct {
+	Text      string
+	Replies   []string
+	Action    Action
+	Sentiment string
+	Timestamp time.Time
 }
 
 type setMemoryForms struct {
-	Memory            map[string]map[string]interface{} `json:"memory"`
-	ConversationToken string                            `json:"conversation_token"`
+	Memory            map[string]interface{} `json:"memory"`
+	ConversationToken string                 `json:"conversation_token"`
 }
 
 // IsPositive returns whether or not the sentiment is positive
@@ -67,93 +78,93 @@ func (conv Conversation) IsVeryNegative() bool {
 	return conv.Sentiment == SentimentVeryNegative
 }
 
-// SetMemory allows to change the conversation memory variables
-func (conv *Conversation) SetMemory(memory map[string]map[string]interface{}) error {
-	httpClient := gorequest.New()
+// requestClient returns the client the conversation was started with
+func (conv *Conversation) requestClient() *RequestClient {
+	if conv.client == nil {
+		return &RequestClient{}
+	}
+	return conv.client
+}
 
+// updateMemory sends memory to the converse endpoint and updates
+// conv.Memory with the memory returned by the API
+func (conv *Conversation) updateMemory(memory map[string]interface{}) error {
+	client := conv.requestClient()
 	send := setMemoryForms{
 		Memory:            memory,
 		ConversationToken: conv.ConversationToken,
 	}
 
-	var response struct {
-		Results *Conversation `json:"results"`
-		Message string        `json:"message"`
+	var results *struct {
+		Memory map[string]interface{} `json:"memory"`
 	}
-
-	resp, _, requestErr := httpClient.
-		Put(converseEndpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", conv.AuthorizationToken)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return requestErr[0]
+	err := doRequest(client.httpClient(), http.MethodPut, endpointURL(client.Endpoint, converseEndpoint), conv.AuthorizationToken, send, &results, http.StatusOK)
+	if err != nil {
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Request failed(%s): %s", resp.Status, response.Message)
+	if results != nil {
+		conv.Memory = results.Memory
 	}
-
 	return nil
 }
 
-// ResetMemory empties all variables in the conversation
-func (conv *Conversation) ResetMemory() error {
-	httpClient := gorequest.New()
-
-	send := struct {
-		Memory            *map[string]map[string]interface{}
-		ConversationToken string
-	}{nil, conv.ConversationToken}
-
-	var response struct {
-		Results *Conversation `json:"results"`
-		Message string        `json:"message"`
-	}
-
-	resp, _, requestErr := httpClient.
-		Put(converseEndpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", conv.AuthorizationToken)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return requestErr[0]
+// SetMemory allows to change the conversation memory variables
+// conv.Memory is updated with the memory returned by the API
+func (conv *Conversation) SetMemory(memory map[string]map[string]interface{}) error {
+	send := make(map[string]interface{}, len(memory))
+	for key, value := range memory {
+		send[key] = value
 	}
-	defer resp.Body.Close()
+	return conv.updateMemory(send)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Request failed(%s): %s", resp.Status, response.Message)
+// ResetMemory empties the given variables in the conversation,
+// or all the variables if no key is given
+//	err := conversation.ResetMemory("location")
+func (conv *Conversation) ResetMemory(keys ...string) error {
+	var send map[string]interface{}
+	if len(keys) > 0 {
+		send = make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			send[key] = nil
+		}
 	}
-
-	return nil
+	return conv.updateMemory(send)
 }
 
 // Reset resets all the conversation (actions and variables)
 func (conv *Conversation) Reset() error {
-	httpClient := gorequest.New()
-
-	var response struct {
-		Message string `json:"message"`
+	client := conv.requestClient()
+	endpoint := endpointURL(client.Endpoint, converseEndpoint) + "?conversation_token=" + url.QueryEscape(conv.ConversationToken)
+	if err := doRequest(client.httpClient(), http.MethodDelete, endpoint, conv.AuthorizationToken, nil, nil, http.StatusOK); err != nil {
+		return err
 	}
+	conv.Memory = nil
+	return nil
+}
 
-	resp, _, requestErr := httpClient.
-		Delete(converseEndpoint+"?conversation_token="+conv.ConversationToken).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", conv.AuthorizationToken)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return requestErr[0]
+// Reply sends the next user sentence of the conversation
+// The token, language and client configuration of the conversation are reused,
+// and conv is updated in place with the response
+//	conversation, err := client.ConverseText("Hello", nil)
+//	err = conversation.Reply(ctx, "What is the weather in London?")
+//	fmt.Println(conversation.Replies)
+func (conv *Conversation) Reply(ctx context.Context, text string) error {
+	client := conv.client
+	if client == nil {
+		client = &RequestClient{}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Request failed(%s): %s", resp.Status, response.Message)
+	next, err := client.converseText(ctx, text, &ConverseOpts{
+		ConversationToken: conv.ConversationToken,
+		Language:          conv.Language,
+		Token:             conv.AuthorizationToken,
+	})
+	if err != nil {
+		return err
 	}
 
+	next.History = append(append([]ConversationTurn(nil), conv.History...), next.History...)
+	*conv = next
 	return nil
 }
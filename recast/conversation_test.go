@@ -1,7 +1,11 @@
 package recast
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -91,3 +95,123 @@ func TestSentimentHelpers(t *testing.T) {
 	expect(!conv.IsNegative(), t, "Should not be negative")
 	expect(!conv.IsVeryNegative(), t, "Should be very negative")
 }
+
+func TestConversationReply(t *testing.T) {
+	var forms []requestForms
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form requestForms
+		json.NewDecoder(r.Body).Decode(&form)
+		forms = append(forms, form)
+		fmt.Fprintf(w, `{"results":{"conversation_token":"conversation_token","language":"fr","replies":["reply %d"]},"message":"Converses rendered with success"}`, len(forms))
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	conv, err := client.ConverseText("Bonjour", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := conv.Reply(context.Background(), "Quel temps fait-il ?"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	if forms[1].ConversationToken != "conversation_token" || forms[1].Language != "fr" {
+		t.Fatalf("The conversation token and language should be reused: %+v", forms[1])
+	}
+	if conv.Replies[0] != "reply 2" || conv.AuthorizationToken != "recast_token" {
+		t.Fatalf("The conversation should be updated in place: %+v", conv)
+	}
+	if len(conv.History) != 2 || conv.History[0].Text != "Bonjour" || conv.History[1].Replies[0] != "reply 2" {
+		t.Fatalf("Unexpected history: %+v", conv.History)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := conv.Reply(ctx, "Merci"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if len(conv.History) != 2 {
+		t.Fatal("A failed reply should not change the conversation")
+	}
+}
+
+func TestConversationReplyHistoryCopy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":{"conversation_token":"conversation_token","replies":["reply"]},"message":"Converses rendered with success"}`)
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	conv, err := client.ConverseText("1", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	for _, text := range []string{"2", "3"} {
+		if err := conv.Reply(context.Background(), text); err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+	}
+
+	// both copies share the history, replying on one must not change the other
+	first, second := conv, conv
+	if err := first.Reply(context.Background(), "first"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := second.Reply(context.Background(), "second"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if first.History[3].Text != "first" || second.History[3].Text != "second" {
+		t.Fatalf("Unexpected histories: %+v %+v", first.History, second.History)
+	}
+}
+
+func TestMemoryPayload(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		memory := map[string]interface{}{"location": map[string]interface{}{"raw": "Paris"}, "name": map[string]interface{}{"raw": "Bob"}}
+		if body["memory"] == nil {
+			memory = map[string]interface{}{}
+		} else if m := body["memory"].(map[string]interface{}); m["location"] == nil {
+			delete(memory, "location")
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": map[string]interface{}{"memory": memory}, "message": "Converse updated with success"})
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	conv := Conversation{AuthorizationToken: "recast_token", ConversationToken: "conversation_token", client: &client}
+
+	if err := conv.SetMemory(map[string]map[string]interface{}{"location": {"raw": "Paris"}}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if bodies[0]["conversation_token"] != "conversation_token" || bodies[0]["memory"] == nil {
+		t.Fatalf("Unexpected payload: %+v", bodies[0])
+	}
+	if len(conv.Memory) != 2 {
+		t.Fatalf("The memory should be updated from the response: %+v", conv.Memory)
+	}
+
+	if err := conv.ResetMemory("location"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if memory, ok := bodies[1]["memory"].(map[string]interface{}); !ok || len(memory) != 1 || memory["location"] != nil {
+		t.Fatalf("Unexpected payload: %+v", bodies[1])
+	}
+	if _, found := conv.Memory["location"]; found || conv.Memory["name"] == nil {
+		t.Fatalf("Only the location should be reset: %+v", conv.Memory)
+	}
+
+	if err := conv.ResetMemory(); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, found := bodies[2]["memory"]; !found || bodies[2]["conversation_token"] != "conversation_token" {
+		t.Fatalf("Unexpected payload: %+v", bodies[2])
+	}
+	if len(conv.Memory) != 0 {
+		t.Fatalf("The memory should be empty: %+v", conv.Memory)
+	}
+}
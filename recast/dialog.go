@@ -1,25 +1,56 @@
 package recast
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 )
 
 //DialogConversation see https://recast.ai/docs/api-reference/#dialog-text
 type DialogConversation struct {
-	ID              string                 `json:"id"`
-	Language        string                 `json:"language"`
-	Skill           string                 `json:"skill"`
-	SkillOccurences int                    `json:"skill_occurences"`
-	SkillStack      []string               `json:"skill_stack"`
-	Memory          map[string]interface{} `json:"memory"`
+	ID              string   `json:"id"`
+	Language        string   `json:"language"`
+	Skill           string   `json:"skill"`
+	SkillOccurences int      `json:"skill_occurences"`
+	SkillStack      []string `json:"skill_stack"`
+	Memory          Memory   `json:"memory"`
+}
+
+// Memory holds the variables of a dialog conversation, keyed by name
+// The variables are usually entities, with at least a raw value
+type Memory map[string]interface{}
+
+// Has returns whether or not the variable key is set
+func (m Memory) Has(key string) bool {
+	value, found := m[key]
+	return found && value != nil
+}
+
+// Raw returns the raw value of the variable key
+// Variables that are plain strings are returned as is
+func (m Memory) Raw(key string) string {
+	switch value := m[key].(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		raw, _ := value["raw"].(string)
+		return raw
+	}
+	return ""
+}
+
+// Decode stores the variable key in the value pointed to by v
+//	var location recast.Location
+//	err := dialog.DialogConversation.Memory.Decode("location", &location)
+func (m Memory) Decode(key string, v interface{}) error {
+	data, err := json.Marshal(m[key])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
 }
 
 type dialogRawMessages struct {
-	Messages []struct {
-		Type    string          `json:"type"`
-		Content json.RawMessage `json:"content"`
-	} `json:"messages"`
+	Messages ComponentList `json:"messages"`
 }
 
 type dialogRawEntities struct {
@@ -33,6 +64,44 @@ type Dialog struct {
 	Messages           []Component        `json:"-"`
 	DialogConversation DialogConversation `json:"conversation"`
 	Nlp                Response           `json:"nlp"`
+	// History holds the turns of the dialog since it was started with DialogText
+	History []DialogTurn `json:"-"`
+
+	client *RequestClient
+	token  string
+}
+
+// DialogTurn is a message sent to the dialog endpoint and the bot answer
+type DialogTurn struct {
+	Text     string
+	Message  DialogMessage
+	Messages []Component
+}
+
+// Continue sends the next user message of the dialog
+// The token, language and client configuration of the dialog are reused,
+// and d is updated in place with the response
+//	dialog, err := client.DialogText("Hello", &recast.DialogOpts{ConversationID: "CONVERSATION_ID"})
+//	err = dialog.Continue(ctx, "What is the weather in London?")
+//	fmt.Println(dialog.Messages)
+func (d *Dialog) Continue(ctx context.Context, text string) error {
+	client := d.client
+	if client == nil {
+		client = &RequestClient{}
+	}
+
+	next, err := client.dialogText(ctx, text, &DialogOpts{
+		ConversationID: d.DialogConversation.ID,
+		Language:       d.DialogConversation.Language,
+		Token:          d.token,
+	})
+	if err != nil {
+		return err
+	}
+
+	next.History = append(append([]DialogTurn(nil), d.History...), next.History...)
+	*d = next
+	return nil
 }
 
 func parseDialog(body json.RawMessage) (Dialog, error) {
@@ -48,9 +117,9 @@ func parseDialog(body json.RawMessage) (Dialog, error) {
 		return Dialog{}, err
 	}
 
-	dialog.Messages, err = parseRawMessages(rawMessages)
-	if err != nil {
-		return Dialog{}, err
+	dialog.Messages = rawMessages.Messages
+	if dialog.Messages == nil {
+		dialog.Messages = []Component{}
 	}
 
 	var rawEntities dialogRawEntities
@@ -62,50 +131,3 @@ func parseDialog(body json.RawMessage) (Dialog, error) {
 
 	return dialog, nil
 }
-
-func parseRawMessages(rawMessages dialogRawMessages) ([]Component, error) {
-	components := make([]Component, 0)
-
-	for _, rawComponent := range rawMessages.Messages {
-		switch rawComponent.Type {
-		case "text", "picture", "video":
-			c := &Attachment{}
-			c.Type = rawComponent.Type
-			if err := json.Unmarshal(rawComponent.Content, &c.Content); err != nil {
-				return nil, err
-			}
-			components = append(components, c)
-		case "quickReplies":
-			c := &QuickReplies{}
-			c.Type = rawComponent.Type
-			if err := json.Unmarshal(rawComponent.Content, &c.Content); err != nil {
-				return nil, err
-			}
-			components = append(components, c)
-		case "carousel":
-			c := &Carousel{}
-			c.Type = rawComponent.Type
-			if err := json.Unmarshal(rawComponent.Content, &c.Content); err != nil {
-				return nil, err
-			}
-			components = append(components, c)
-		case "list":
-			c := &List{}
-			c.Type = rawComponent.Type
-			if err := json.Unmarshal(rawComponent.Content, &c.Content); err != nil {
-				return nil, err
-			}
-			components = append(components, c)
-		case "card":
-			c := &Card{}
-			c.Type = rawComponent.Type
-			if err := json.Unmarshal(rawComponent.Content, &c.Content); err != nil {
-				return nil, err
-			}
-			components = append(components, c)
-		default:
-			return nil, fmt.Errorf("Unknown message type: %s", rawComponent.Type)
-		}
-	}
-	return components, nil
-}
@@ -0,0 +1,95 @@
+package recast
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNoBotSlug is returned when a dialog conversation is managed without user and bot slugs
+var ErrNoBotSlug = errors.New("The user slug or bot slug is empty")
+
+type dialogConversationForm struct {
+	Memory          Memory    `json:"memory"`
+	Skill           *string   `json:"skill,omitempty"`
+	SkillOccurences *int      `json:"skill_occurences,omitempty"`
+	SkillStack      *[]string `json:"skill_stack,omitempty"`
+}
+
+// conversationStateURL returns the build API location of a dialog conversation
+func (c *RequestClient) conversationStateURL(conversationID string) (string, error) {
+	if c.UserSlug == "" || c.BotSlug == "" {
+		return "", ErrNoBotSlug
+	}
+	if conversationID == "" {
+		return "", ErrNoRequestConversationID
+	}
+	if c.Token == "" {
+		return "", ErrTokenNotSet
+	}
+
+	version := c.BotVersion
+	if version == "" {
+		version = "v1"
+	}
+	endpoint := buildEndpoint + "users/" + c.UserSlug + "/bots/" + c.BotSlug + "/versions/" + version + "/builder/conversation_states/" + conversationID
+	return endpointURL(c.Endpoint, endpoint), nil
+}
+
+// GetDialogConversation fetches the state of a dialog conversation
+//	client := recast.RequestClient{Token: "YOUR_TOKEN", UserSlug: "USER", BotSlug: "BOT"}
+//	conversation, err := client.GetDialogConversation("CONVERSATION_ID")
+func (c *RequestClient) GetDialogConversation(conversationID string) (DialogConversation, error) {
+	endpoint, err := c.conversationStateURL(conversationID)
+	if err != nil {
+		return DialogConversation{}, err
+	}
+
+	var conversation DialogConversation
+	if err := doRequest(c.httpClient(), http.MethodGet, endpoint, c.Token, nil, &conversation, http.StatusOK); err != nil {
+		return DialogConversation{}, err
+	}
+	return conversation, nil
+}
+
+// UpdateDialogMemory replaces the memory of a dialog conversation
+// and returns the updated conversation
+func (c *RequestClient) UpdateDialogMemory(conversationID string, memory Memory) (DialogConversation, error) {
+	if memory == nil {
+		memory = Memory{}
+	}
+	return c.updateConversationState(conversationID, dialogConversationForm{Memory: memory})
+}
+
+// ResetDialogConversation empties the memory and the skill stack of a dialog conversation,
+// the next message starts the conversation over
+func (c *RequestClient) ResetDialogConversation(conversationID string) (DialogConversation, error) {
+	skill, occurences, stack := "", 0, []string{}
+	return c.updateConversationState(conversationID, dialogConversationForm{
+		Memory:          Memory{},
+		Skill:           &skill,
+		SkillOccurences: &occurences,
+		SkillStack:      &stack,
+	})
+}
+
+func (c *RequestClient) updateConversationState(conversationID string, send dialogConversationForm) (DialogConversation, error) {
+	endpoint, err := c.conversationStateURL(conversationID)
+	if err != nil {
+		return DialogConversation{}, err
+	}
+
+	var conversation DialogConversation
+	if err := doRequest(c.httpClient(), http.MethodPut, endpoint, c.Token, send, &conversation, http.StatusOK); err != nil {
+		return DialogConversation{}, err
+	}
+	return conversation, nil
+}
+
+// DeleteDialogConversation deletes a dialog conversation and its state
+func (c *RequestClient) DeleteDialogConversation(conversationID string) error {
+	endpoint, err := c.conversationStateURL(conversationID)
+	if err != nil {
+		return err
+	}
+	return doRequest(c.httpClient(), http.MethodDelete, endpoint, c.Token, nil, nil, http.StatusOK, http.StatusNoContent)
+}
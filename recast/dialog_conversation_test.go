@@ -0,0 +1,63 @@
+package recast
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDialogConversationManagement(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"results":{"id":"conversation_id","language":"en","skill":"greetings","memory":{"name":{"raw":"Bob"}}},"message":"Conversation rendered with success"}`))
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL, UserSlug: "user", BotSlug: "bot"}
+
+	conversation, err := client.GetDialogConversation("conversation_id")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if requests[0].Method != http.MethodGet || requests[0].URL.Path != "/build/v1/users/user/bots/bot/versions/v1/builder/conversation_states/conversation_id" {
+		t.Fatalf("Unexpected request: %s %s", requests[0].Method, requests[0].URL.Path)
+	}
+	if conversation.Skill != "greetings" || conversation.Memory.Raw("name") != "Bob" {
+		t.Fatalf("Unexpected conversation: %+v", conversation)
+	}
+
+	if _, err := client.UpdateDialogMemory("conversation_id", Memory{"name": map[string]interface{}{"raw": "Bob"}}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if requests[1].Method != http.MethodPut || bodies[1]["memory"] == nil || bodies[1]["skill_stack"] != nil {
+		t.Fatalf("Unexpected request: %s %+v", requests[1].Method, bodies[1])
+	}
+
+	if _, err := client.ResetDialogConversation("conversation_id"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if memory, ok := bodies[2]["memory"].(map[string]interface{}); !ok || len(memory) != 0 || bodies[2]["skill_stack"] == nil {
+		t.Fatalf("Unexpected payload: %+v", bodies[2])
+	}
+
+	if err := client.DeleteDialogConversation("conversation_id"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	if _, err := (&RequestClient{Token: "recast_token"}).GetDialogConversation("conversation_id"); err != ErrNoBotSlug {
+		t.Fatalf("Expected ErrNoBotSlug, but instead got %+v", err)
+	}
+	if err := client.DeleteDialogConversation(""); err != ErrNoRequestConversationID {
+		t.Fatalf("Expected ErrNoRequestConversationID, but instead got %+v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package recast
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -12,3 +17,83 @@ func TestJsonUnmarshal(t *testing.T) {
 		t.Fatalf("ParseDialog error: %+v", err)
 	}
 }
+
+func TestDialogContinue(t *testing.T) {
+	var forms []dialogForm
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form dialogForm
+		json.NewDecoder(r.Body).Decode(&form)
+		forms = append(forms, form)
+		fmt.Fprintf(w, `{"results":{"messages":[{"type":"text","content":"reply %d"}],"conversation":{"id":"%s","language":"en","memory":{}},"nlp":{}},"message":"Dialog rendered with success"}`, len(forms), form.ConversationID)
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	dialog, err := client.DialogText("Hello", &DialogOpts{ConversationID: "conversation_id"})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := dialog.Continue(context.Background(), "How are you?"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	if forms[1].ConversationID != "conversation_id" || forms[1].Language != "en" || forms[1].Message.Content != "How are you?" {
+		t.Fatalf("The conversation ID and language should be reused: %+v", forms[1])
+	}
+	if text, ok := dialog.Messages[0].(*Attachment); !ok || text.Content != "reply 2" {
+		t.Fatalf("The dialog should be updated in place: %+v", dialog.Messages)
+	}
+	if len(dialog.History) != 2 || dialog.History[0].Text != "Hello" || dialog.History[1].Text != "How are you?" {
+		t.Fatalf("Unexpected history: %+v", dialog.History)
+	}
+
+	if err := (&Dialog{}).Continue(context.Background(), "Hello"); err != ErrTokenNotSet {
+		t.Fatalf("Expected ErrTokenNotSet, but instead got %+v", err)
+	}
+}
+
+func TestDialogOpts(t *testing.T) {
+	var forms []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&form)
+		forms = append(forms, form)
+		memory, _ := json.Marshal(form["memory"])
+		fmt.Fprintf(w, `{"results":{"messages":[],"conversation":{"id":"conversation_id","language":"en","memory":%s},"nlp":{}},"message":"Dialog rendered with success"}`, memory)
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	dialog, err := client.DialogText("", &DialogOpts{
+		ConversationID: "conversation_id",
+		Memory:         map[string]interface{}{"location": map[string]interface{}{"raw": "Paris"}, "name": "Bob"},
+		LogLevel:       "debug",
+		Message:        NewDialogPostback("START"),
+	})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	message := forms[0]["message"].(map[string]interface{})
+	if message["type"] != "postback" || message["content"] != "START" || forms[0]["log_level"] != "debug" {
+		t.Fatalf("Unexpected payload: %+v", forms[0])
+	}
+
+	memory := dialog.DialogConversation.Memory
+	if !memory.Has("location") || memory.Has("date") || memory.Raw("location") != "Paris" || memory.Raw("name") != "Bob" {
+		t.Fatalf("Unexpected memory: %+v", memory)
+	}
+	var location Location
+	if err := memory.Decode("location", &location); err != nil || location.Raw != "Paris" {
+		t.Fatalf("Unexpected location: %+v %+v", location, err)
+	}
+
+	client.DialogText("", &DialogOpts{Message: NewDialogQuickReply("Yes", "yes")})
+	message = forms[1]["message"].(map[string]interface{})
+	if content, ok := message["content"].(map[string]interface{}); message["type"] != "quickReply" || !ok || content["value"] != "yes" {
+		t.Fatalf("Unexpected payload: %+v", forms[1])
+	}
+	if _, found := forms[1]["memory"]; found {
+		t.Fatal("The memory should be omitted when it is not set")
+	}
+}
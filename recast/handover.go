@@ -0,0 +1,461 @@
+package recast
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotHandedOver is returned when releasing a conversation owned by the bot
+	ErrNotHandedOver = errors.New("The conversation is not handed over")
+	// ErrAgentQueueFull is returned when a QueueForwarder cannot take more events
+	ErrAgentQueueFull = errors.New("The agent queue is full")
+)
+
+// Handover is a conversation owned by a human agent
+type Handover struct {
+	ConversationID string    `json:"conversation_id"`
+	ChannelType    string    `json:"channel_type"`
+	SenderID       string    `json:"sender_id"`
+	Reason         string    `json:"reason"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// HandoverStore is implemented by the storage backends of the handovers
+// Handovers are keyed by connector conversation ID
+type HandoverStore interface {
+	// Load returns the handover of a conversation
+	// found is false when the conversation is owned by the bot
+	Load(conversationID string) (handover Handover, found bool, err error)
+	// Save marks a conversation as owned by a human agent
+	Save(conversationID string, handover Handover) error
+	// Delete gives the conversation back to the bot
+	Delete(conversationID string) error
+}
+
+// MemoryHandoverStore keeps the handovers in memory
+// It is safe for concurrent use
+type MemoryHandoverStore struct {
+	mu        sync.RWMutex
+	handovers map[string]Handover
+}
+
+// NewMemoryHandoverStore returns an empty MemoryHandoverStore
+func NewMemoryHandoverStore() *MemoryHandoverStore {
+	return &MemoryHandoverStore{handovers: map[string]Handover{}}
+}
+
+// Load implements HandoverStore
+func (s *MemoryHandoverStore) Load(conversationID string) (Handover, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handover, found := s.handovers[conversationID]
+	return handover, found, nil
+}
+
+// Save implements HandoverStore
+func (s *MemoryHandoverStore) Save(conversationID string, handover Handover) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handovers[conversationID] = handover
+	return nil
+}
+
+// Delete implements HandoverStore
+func (s *MemoryHandoverStore) Delete(conversationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.handovers, conversationID)
+	return nil
+}
+
+// RedisHandoverStore keeps the handovers in a Redis compatible store,
+// so that they are shared by several instances of the bot
+type RedisHandoverStore struct {
+	Client RedisClient
+	// Prefix is prepended to all the conversation IDs
+	Prefix string
+	// TTL releases the conversations automatically, they never expire if it is zero
+	TTL time.Duration
+}
+
+// Load implements HandoverStore
+func (s *RedisHandoverStore) Load(conversationID string) (Handover, bool, error) {
+	data, found, err := s.Client.Get(s.Prefix + conversationID)
+	if err != nil || !found {
+		return Handover{}, false, err
+	}
+	var handover Handover
+	if err := json.Unmarshal(data, &handover); err != nil {
+		return Handover{}, false, err
+	}
+	return handover, true, nil
+}
+
+// Save implements HandoverStore
+func (s *RedisHandoverStore) Save(conversationID string, handover Handover) error {
+	data, err := json.Marshal(handover)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(s.Prefix+conversationID, data, s.TTL)
+}
+
+// Delete implements HandoverStore
+func (s *RedisHandoverStore) Delete(conversationID string) error {
+	return s.Client.Del(s.Prefix + conversationID)
+}
+
+// Agent event types
+const (
+	// AgentEventStart is sent when a conversation is handed over
+	AgentEventStart = "start"
+	// AgentEventMessage is sent for each message of a handed over conversation
+	AgentEventMessage = "message"
+	// AgentEventRelease is sent when the conversation is given back to the bot
+	AgentEventRelease = "release"
+)
+
+// AgentEvent is sent to the human agents during a handover
+type AgentEvent struct {
+	Type     string   `json:"type"`
+	Handover Handover `json:"handover"`
+	// Message is the message of the user for the AgentEventMessage events
+	Message *Message `json:"message,omitempty"`
+}
+
+// AgentForwarder sends the handover events to the human agents
+type AgentForwarder interface {
+	Forward(event AgentEvent) error
+}
+
+// AgentForwarderFunc is a function used as an AgentForwarder
+type AgentForwarderFunc func(event AgentEvent) error
+
+// Forward implements AgentForwarder
+func (f AgentForwarderFunc) Forward(event AgentEvent) error {
+	return f(event)
+}
+
+// WebhookForwarder posts the events as JSON to the webhook of an agent platform
+type WebhookForwarder struct {
+	URL string
+	// Token is sent in the Authorization header
+	Token string
+	// Transport is used to perform the requests when set
+	Transport http.RoundTripper
+}
+
+// Forward implements AgentForwarder
+func (f *WebhookForwarder) Forward(event AgentEvent) error {
+	return doRequest(newHTTPClient(nil, f.Transport), http.MethodPost, f.URL, f.Token, event, nil, http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent)
+}
+
+// QueueForwarder sends the events to a channel read by the agent platform
+// Events are dropped with ErrAgentQueueFull when the channel is full
+type QueueForwarder chan AgentEvent
+
+// Forward implements AgentForwarder
+func (q QueueForwarder) Forward(event AgentEvent) error {
+	select {
+	case q <- event:
+		return nil
+	default:
+		return ErrAgentQueueFull
+	}
+}
+
+// HandoverManager routes the messages of the conversations handed over to human agents
+// Messages of the other conversations are served by the bot handler,
+// which can hand a conversation over with Escalate or EscalateOn
+//	manager := recast.NewHandoverManager(client, &recast.WebhookForwarder{URL: "https://agents.example.com/events", Token: "AGENT_TOKEN"}, bot)
+//	client.UseHandler(manager)
+//	http.Handle("/release", manager.ReleaseHandler())
+type HandoverManager struct {
+	// Client sends the agent replies and the handover notices
+	Client *ConnectClient
+	// Store holds the conversations owned by the agents, in memory by default
+	Store HandoverStore
+	// Forwarder receives the events of the handovers, they are dropped if nil
+	Forwarder AgentForwarder
+	// Bot serves the messages of the conversations owned by the bot
+	Bot MessageHandler
+	// Intents escalate the conversation when matched by EscalateOn
+	Intents []string
+	// StartMessage and ReleaseMessage are sent to the user when the conversation
+	// is handed over and given back to the bot, if set
+	StartMessage   Component
+	ReleaseMessage Component
+	// OnError is called with the errors which cannot be returned, they are ignored if nil
+	OnError func(error)
+	// ReleaseToken authenticates the release signals, the agent platform sends it
+	// in the Authorization header as "Token RELEASE_TOKEN"
+	// The token of a WebhookForwarder is used if empty, signals are rejected if there is none
+	ReleaseToken string
+	// TrackerTTL forgets the sentiments of the conversations with no response
+	// for this duration, one hour if zero
+	TrackerTTL time.Duration
+
+	locks    keyLocks
+	mu       sync.Mutex
+	trackers map[string]*trackedConversation
+	sweptAt  time.Time
+}
+
+// trackedConversation is the sentiment tracker of a conversation with its last use
+type trackedConversation struct {
+	tracker *SentimentTracker
+	usedAt  time.Time
+}
+
+// NewHandoverManager returns a manager storing the handovers in memory,
+// escalating the conversations on the talk-to-human intent
+func NewHandoverManager(client *ConnectClient, forwarder AgentForwarder, bot MessageHandler) *HandoverManager {
+	return &HandoverManager{
+		Client:    client,
+		Store:     NewMemoryHandoverStore(),
+		Forwarder: forwarder,
+		Bot:       bot,
+		Intents:   []string{"talk-to-human"},
+	}
+}
+
+// ServeMessage implements MessageHandler
+// Messages of handed over conversations are forwarded to the agents instead of the bot
+// They are routed under the lock of the conversation, which is released before
+// calling the bot so that it can escalate
+func (h *HandoverManager) ServeMessage(w MessageWriter, m Message) {
+	if found := h.forwardMessage(m); !found && h.Bot != nil {
+		h.Bot.ServeMessage(w, m)
+	}
+}
+
+// forwardMessage forwards m to the agents if its conversation is handed over
+func (h *HandoverManager) forwardMessage(m Message) bool {
+	defer h.locks.lock(m.ConversationID)()
+	handover, found, err := h.Store.Load(m.ConversationID)
+	if err != nil {
+		h.fail(err)
+		return true
+	}
+	if found {
+		h.forward(AgentEvent{Type: AgentEventMessage, Handover: handover, Message: &m})
+	}
+	return found
+}
+
+// IsHandedOver returns whether or not the conversation is owned by a human agent
+func (h *HandoverManager) IsHandedOver(conversationID string) (bool, error) {
+	_, found, err := h.Store.Load(conversationID)
+	return found, err
+}
+
+// Escalate hands the conversation of ctx over to the agents
+// Escalating a conversation already handed over does nothing
+// Escalations and releases of a conversation are serialized within the process
+// The conversation is not handed over if the start event cannot be forwarded
+func (h *HandoverManager) Escalate(ctx *Context, reason string) error {
+	if ctx == nil || ctx.ConversationID == "" {
+		return ErrNoRequestConversationID
+	}
+	defer h.locks.lock(ctx.ConversationID)()
+	if _, found, err := h.Store.Load(ctx.ConversationID); err != nil || found {
+		return err
+	}
+
+	handover := Handover{
+		ConversationID: ctx.ConversationID,
+		ChannelType:    ctx.ChannelType,
+		SenderID:       ctx.SenderID,
+		Reason:         reason,
+		StartedAt:      time.Now(),
+	}
+	if err := h.Store.Save(ctx.ConversationID, handover); err != nil {
+		return err
+	}
+	if err := h.send(AgentEvent{Type: AgentEventStart, Handover: handover}); err != nil {
+		if deleteErr := h.Store.Delete(ctx.ConversationID); deleteErr != nil {
+			h.fail(deleteErr)
+		}
+		return err
+	}
+	h.resetTracker(ctx.ConversationID)
+
+	if h.StartMessage != nil && h.Client != nil {
+		if err := h.Client.sendMessage(ctx.ConversationID, ctx.ChannelType, []Component{h.StartMessage}); err != nil {
+			h.fail(err)
+		}
+	}
+	return nil
+}
+
+// EscalateOn hands the conversation of ctx over when the response matches one of
+// the Intents, or when the sentiment of the conversation requires it
+// The sentiments of the responses are tracked by conversation with a SentimentTracker
+//	response, err := request.AnalyzeText(m.Attachment.Content, nil)
+//	if escalated, err := manager.EscalateOn(w.Context(), response); escalated || err != nil {
+//		return
+//	}
+func (h *HandoverManager) EscalateOn(ctx *Context, r Response) (bool, error) {
+	if ctx == nil || ctx.ConversationID == "" {
+		return false, ErrNoRequestConversationID
+	}
+
+	reason := ""
+	if intent, err := r.Intent(); err == nil {
+		for _, slug := range h.Intents {
+			if intent.Slug == slug {
+				reason = "intent:" + slug
+				break
+			}
+		}
+	}
+	tracker := h.tracker(ctx.ConversationID).AddResponse(r)
+	if reason == "" && tracker.ShouldEscalate() {
+		reason = "sentiment:" + tracker.Current().String()
+	}
+	if reason == "" {
+		return false, nil
+	}
+	return true, h.Escalate(ctx, reason)
+}
+
+// Release gives the conversation back to the bot, it is the release signal of the agents
+func (h *HandoverManager) Release(conversationID string) error {
+	defer h.locks.lock(conversationID)()
+	handover, found, err := h.Store.Load(conversationID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotHandedOver
+	}
+	if err := h.Store.Delete(conversationID); err != nil {
+		return err
+	}
+	h.resetTracker(conversationID)
+
+	if h.ReleaseMessage != nil && h.Client != nil {
+		if err := h.Client.sendMessage(conversationID, handover.ChannelType, []Component{h.ReleaseMessage}); err != nil {
+			h.fail(err)
+		}
+	}
+	return h.send(AgentEvent{Type: AgentEventRelease, Handover: handover})
+}
+
+// Reply sends the messages of an agent to a handed over conversation
+func (h *HandoverManager) Reply(conversationID string, messages ...Component) error {
+	handover, found, err := h.Store.Load(conversationID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotHandedOver
+	}
+	return h.Client.sendMessage(conversationID, handover.ChannelType, messages)
+}
+
+// ReleaseHandler returns the handler of the release signals sent by the agent platform
+// It expects a POST request authenticated with ReleaseToken,
+// with a JSON body such as {"conversation_id": "CONVERSATION_ID"}
+func (h *HandoverManager) ReleaseHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		token := h.releaseToken()
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Token "+token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var signal struct {
+			ConversationID string `json:"conversation_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&signal); err != nil || signal.ConversationID == "" {
+			http.Error(w, "Invalid Content:", http.StatusBadRequest)
+			return
+		}
+
+		switch err := h.Release(signal.ConversationID); err {
+		case nil:
+			w.WriteHeader(http.StatusOK)
+		case ErrNotHandedOver:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (h *HandoverManager) releaseToken() string {
+	if h.ReleaseToken != "" {
+		return h.ReleaseToken
+	}
+	if forwarder, ok := h.Forwarder.(*WebhookForwarder); ok && forwarder != nil {
+		return forwarder.Token
+	}
+	return ""
+}
+
+// tracker returns the sentiment tracker of a conversation,
+// the trackers of the inactive conversations are removed on the way
+func (h *HandoverManager) tracker(conversationID string) *SentimentTracker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.trackers == nil {
+		h.trackers = map[string]*trackedConversation{}
+	}
+
+	now := time.Now()
+	ttl := h.TrackerTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	if now.Sub(h.sweptAt) >= ttl {
+		for id, tracked := range h.trackers {
+			if now.Sub(tracked.usedAt) >= ttl {
+				delete(h.trackers, id)
+			}
+		}
+		h.sweptAt = now
+	}
+
+	tracked, found := h.trackers[conversationID]
+	if !found || now.Sub(tracked.usedAt) >= ttl {
+		tracked = &trackedConversation{tracker: NewSentimentTracker()}
+		h.trackers[conversationID] = tracked
+	}
+	tracked.usedAt = now
+	return tracked.tracker
+}
+
+func (h *HandoverManager) resetTracker(conversationID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.trackers, conversationID)
+}
+
+// send forwards event to the agents, it is dropped if there is no Forwarder
+func (h *HandoverManager) send(event AgentEvent) error {
+	if h.Forwarder == nil {
+		return nil
+	}
+	return h.Forwarder.Forward(event)
+}
+
+func (h *HandoverManager) forward(event AgentEvent) {
+	if err := h.send(event); err != nil {
+		h.fail(err)
+	}
+}
+
+func (h *HandoverManager) fail(err error) {
+	if h.OnError != nil {
+		h.OnError(err)
+	}
+}
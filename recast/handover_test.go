@@ -0,0 +1,277 @@
+package recast
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newHandoverTestManager(t *testing.T) (*HandoverManager, *[]string, *[]Message, func()) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []Attachment `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, m := range body.Messages {
+			sent = append(sent, m.Content)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	client := NewConnectClient("recast_token")
+	client.Endpoint = server.URL
+
+	var served []Message
+	bot := MessageHandlerFunc(func(w MessageWriter, m Message) {
+		served = append(served, m)
+	})
+	manager := NewHandoverManager(client, nil, bot)
+	manager.StartMessage = NewTextMessage("An agent is coming")
+	manager.ReleaseMessage = NewTextMessage("Back to the bot")
+	manager.OnError = func(err error) {
+		t.Fatalf("Expected no error, but instead got %+v", err)
+	}
+	return manager, &sent, &served, server.Close
+}
+
+func newReleaseRequest(conversationID, token string) *http.Request {
+	r := httptest.NewRequest("POST", "/release", strings.NewReader(`{"conversation_id":"`+conversationID+`"}`))
+	r.Header.Set("Authorization", "Token "+token)
+	return r
+}
+
+func TestHandoverManager(t *testing.T) {
+	manager, sent, served, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+	queue := make(QueueForwarder, 10)
+	manager.Forwarder = queue
+
+	ctx := &Context{ConversationID: "c1", ChannelType: ChannelSlack, SenderID: "U1"}
+	writer := &messageWriter{client: manager.Client, context: ctx}
+	message := Message{ConversationID: "c1", Attachment: Attachment{Type: "text", Content: "Hello"}}
+
+	manager.ServeMessage(writer, message)
+	if len(*served) != 1 || len(queue) != 0 {
+		t.Fatal("The message should be served by the bot")
+	}
+
+	escalated, err := manager.EscalateOn(ctx, Response{Intents: []Intent{{Slug: "talk-to-human"}}})
+	if err != nil || !escalated {
+		t.Fatalf("Expected the conversation to be escalated, got %v %+v", escalated, err)
+	}
+	if event := <-queue; event.Type != AgentEventStart || event.Handover.Reason != "intent:talk-to-human" || event.Handover.ChannelType != ChannelSlack {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+	if handedOver, _ := manager.IsHandedOver("c1"); !handedOver {
+		t.Fatal("The conversation should be handed over")
+	}
+
+	manager.ServeMessage(writer, message)
+	if len(*served) != 1 {
+		t.Fatal("The message should not be served by the bot")
+	}
+	if event := <-queue; event.Type != AgentEventMessage || event.Message.Attachment.Content != "Hello" {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+
+	if err := manager.Reply("c1", NewTextMessage("Hi, I am Jane")); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	// the agent platform sends the release signal
+	rr := httptest.NewRecorder()
+	manager.ReleaseHandler().ServeHTTP(rr, newReleaseRequest("c1", "release_token"))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401 with no release token, but instead got %d", rr.Code)
+	}
+	manager.ReleaseToken = "release_token"
+	rr = httptest.NewRecorder()
+	manager.ReleaseHandler().ServeHTTP(rr, newReleaseRequest("c1", "wrong_token"))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401, but instead got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	manager.ReleaseHandler().ServeHTTP(rr, newReleaseRequest("c1", "release_token"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, but instead got %d", rr.Code)
+	}
+	if event := <-queue; event.Type != AgentEventRelease {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+
+	manager.ServeMessage(writer, message)
+	if len(*served) != 2 {
+		t.Fatal("The message should be served by the bot after the release")
+	}
+
+	expected := []string{"An agent is coming", "Hi, I am Jane", "Back to the bot"}
+	if strings.Join(*sent, "|") != strings.Join(expected, "|") {
+		t.Fatalf("Expected messages %v, but instead got %v", expected, *sent)
+	}
+
+	if err := manager.Release("c1"); err != ErrNotHandedOver {
+		t.Fatalf("Expected ErrNotHandedOver, but instead got %v", err)
+	}
+	rr = httptest.NewRecorder()
+	manager.ReleaseHandler().ServeHTTP(rr, newReleaseRequest("c1", "release_token"))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404, but instead got %d", rr.Code)
+	}
+}
+
+func TestHandoverSentimentEscalation(t *testing.T) {
+	manager, _, _, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+	var events []AgentEvent
+	manager.Forwarder = AgentForwarderFunc(func(event AgentEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	ctx := &Context{ConversationID: "c2"}
+	for i, sentiment := range []string{"negative", "negative", "vnegative"} {
+		escalated, err := manager.EscalateOn(ctx, Response{Sentiment: sentiment})
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if escalated != (i == 2) {
+			t.Fatalf("Turn %d: unexpected escalation %v", i, escalated)
+		}
+	}
+	if len(events) != 1 || events[0].Handover.Reason != "sentiment:negative" {
+		t.Fatalf("Unexpected events %+v", events)
+	}
+}
+
+func TestHandoverNoForwarder(t *testing.T) {
+	manager, sent, _, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+
+	ctx := &Context{ConversationID: "c3"}
+	if err := manager.Escalate(ctx, "test"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	manager.ServeMessage(&messageWriter{client: manager.Client, context: ctx}, Message{ConversationID: "c3"})
+	if err := manager.Release("c3"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(*sent) != 2 {
+		t.Fatalf("The notices should be sent with no forwarder, got %v", *sent)
+	}
+}
+
+func TestHandoverConcurrentEscalations(t *testing.T) {
+	manager, sent, _, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+	queue := make(QueueForwarder, 10)
+	manager.Forwarder = queue
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := manager.Escalate(&Context{ConversationID: "c4"}, "test"); err != nil {
+				t.Errorf("Expected err to be nil, but instead got %+v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(queue) != 1 || len(*sent) != 1 {
+		t.Fatalf("The conversation should be handed over once, got %d events and %d messages", len(queue), len(*sent))
+	}
+}
+
+func TestHandoverForwardingFailure(t *testing.T) {
+	manager, sent, served, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+	queue := make(QueueForwarder, 1)
+	queue <- AgentEvent{}
+	manager.Forwarder = queue
+
+	ctx := &Context{ConversationID: "c5"}
+	if err := manager.Escalate(ctx, "test"); err != ErrAgentQueueFull {
+		t.Fatalf("Expected ErrAgentQueueFull, but instead got %v", err)
+	}
+	if handedOver, _ := manager.IsHandedOver("c5"); handedOver {
+		t.Fatal("The conversation should not be handed over when the start event is not forwarded")
+	}
+	if len(*sent) != 0 {
+		t.Fatalf("The start message should not be sent, got %v", *sent)
+	}
+
+	// the bot escalates again once the queue is drained
+	<-queue
+	manager.Bot = MessageHandlerFunc(func(w MessageWriter, m Message) {
+		*served = append(*served, m)
+		if err := manager.Escalate(ctx, "retry"); err != nil {
+			t.Errorf("Expected err to be nil, but instead got %+v", err)
+		}
+	})
+	manager.ServeMessage(&messageWriter{client: manager.Client, context: ctx}, Message{ConversationID: "c5"})
+	if len(*served) != 1 {
+		t.Fatal("The message should be served by the bot")
+	}
+	if event := <-queue; event.Type != AgentEventStart || event.Handover.Reason != "retry" {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+	if handedOver, _ := manager.IsHandedOver("c5"); !handedOver {
+		t.Fatal("The conversation should be handed over")
+	}
+}
+
+func TestHandoverTrackers(t *testing.T) {
+	manager, _, _, closeServer := newHandoverTestManager(t)
+	defer closeServer()
+	manager.TrackerTTL = time.Millisecond
+
+	ctx := &Context{ConversationID: "c5"}
+	if _, err := manager.EscalateOn(ctx, Response{Sentiment: "negative"}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err := manager.EscalateOn(&Context{ConversationID: "c6"}, Response{Sentiment: "neutral"}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, found := manager.trackers["c5"]; found || len(manager.trackers) != 1 {
+		t.Fatalf("The trackers of the inactive conversations should be removed, got %d", len(manager.trackers))
+	}
+
+	manager.TrackerTTL = 0
+	if err := manager.Escalate(&Context{ConversationID: "c6"}, "test"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := manager.Release("c6"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(manager.trackers) != 0 {
+		t.Fatal("The tracker should be removed on escalation and release")
+	}
+}
+
+func TestWebhookForwarder(t *testing.T) {
+	var event AgentEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token agent_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	forwarder := &WebhookForwarder{URL: server.URL, Token: "agent_token"}
+	err := forwarder.Forward(AgentEvent{Type: AgentEventStart, Handover: Handover{ConversationID: "c1", Reason: "intent:talk-to-human"}})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if event.Type != AgentEventStart || event.Handover.ConversationID != "c1" {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,193 @@
+package recast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// apiResponse is the envelope of every Recast.AI API response
+type apiResponse struct {
+	Results json.RawMessage `json:"results"`
+	Message string          `json:"message"`
+}
+
+// endpointURL returns the URL of endpoint, replacing the Recast.AI API
+// location by root when it is set
+func endpointURL(root, endpoint string) string {
+	if root == "" {
+		return endpoint
+	}
+	return strings.TrimSuffix(root, "/") + "/" + strings.TrimPrefix(endpoint, apiEndpoint)
+}
+
+// defaultClient sends the requests of the clients with no HTTPClient nor Transport
+// The proxy set in RECAST_PROXY is resolved once, when the first request is sent
+var defaultClient struct {
+	once   sync.Once
+	client *http.Client
+}
+
+func defaultHTTPClient() *http.Client {
+	defaultClient.once.Do(func() {
+		defaultClient.client = &http.Client{}
+		proxy := os.Getenv("RECAST_PROXY")
+		if proxy == "" {
+			return
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			defaultClient.client.Transport = errTransport{fmt.Errorf("Invalid RECAST_PROXY: %v", err)}
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		defaultClient.client.Transport = transport
+	})
+	return defaultClient.client
+}
+
+// errTransport fails every request with err
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// newHTTPClient returns the client sending the requests of an SDK client:
+// client when set, a client going through transport when set, or the default client
+func newHTTPClient(client *http.Client, transport http.RoundTripper) *http.Client {
+	if client != nil {
+		return client
+	}
+	if transport != nil {
+		return &http.Client{Transport: transport}
+	}
+	return defaultHTTPClient()
+}
+
+// newRequest returns a request within ctx authenticated with token,
+// with send encoded as its JSON body if it is not nil
+func newRequest(ctx context.Context, method, endpoint, token string, send interface{}) (*http.Request, error) {
+	var body io.Reader
+	if send != nil {
+		data, err := json.Marshal(send)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if send != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+	return req.WithContext(ctx), nil
+}
+
+// newFileRequest returns a multipart request within ctx authenticated with token,
+// sending content as the file field and fields as form values
+func newFileRequest(ctx context.Context, method, endpoint, token, field, filename string, content []byte, fields map[string]string) (*http.Request, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile(field, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := w.WriteField(key, fields[key]); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+	return req.WithContext(ctx), nil
+}
+
+// end sends req with client and returns the response with its body
+func end(client *http.Client, req *http.Request) (*http.Response, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, body, nil
+}
+
+// doRequest sends a JSON request authenticated with token with client and decodes
+// the results of the response into results if it is not nil
+// An error is returned if the response status is not one of expected
+func doRequest(client *http.Client, method, endpoint, token string, send, results interface{}, expected ...int) error {
+	req, err := newRequest(context.Background(), method, endpoint, token, send)
+	if err != nil {
+		return err
+	}
+	resp, body, err := end(client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &response); err != nil {
+			return err
+		}
+	}
+
+	if !isExpectedStatus(resp.StatusCode, expected) {
+		return fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
+	}
+
+	if results == nil || len(response.Results) == 0 || string(response.Results) == "null" {
+		return nil
+	}
+	return json.Unmarshal(response.Results, results)
+}
+
+func isExpectedStatus(status int, expected []int) bool {
+	for _, s := range expected {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package recast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ctxKey string
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":{"conversation_token":"conversation_token","replies":["reply"]},"message":"Converses rendered with success"}`)
+	}))
+	defer server.Close()
+
+	var sent []*http.Request
+	httpClient := &http.Client{Transport: transportFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	client := RequestClient{
+		Token:      "recast_token",
+		Endpoint:   server.URL,
+		HTTPClient: httpClient,
+		Transport: transportFunc(func(r *http.Request) (*http.Response, error) {
+			t.Fatal("The transport should not be used when HTTPClient is set")
+			return nil, nil
+		}),
+	}
+
+	ctx := context.WithValue(context.TODO(), ctxKey("key"), "value")
+	if _, err := client.converseText(ctx, "Bonjour", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 request through the HTTP client, but instead got %d", len(sent))
+	}
+	if sent[0].Context().Value(ctxKey("key")) != "value" {
+		t.Fatal("The request should be sent within the given context")
+	}
+	if auth := sent[0].Header.Get("Authorization"); auth != "Token recast_token" {
+		t.Fatalf("Unexpected Authorization header: %s", auth)
+	}
+	if typ := sent[0].Header.Get("Content-Type"); typ != "application/json" {
+		t.Fatalf("Unexpected Content-Type header: %s", typ)
+	}
+
+	canceled, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, err := client.converseText(canceled, "Bonjour", nil); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
+
+func TestAnalyzeFileForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, `{"message":"invalid form"}`, http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("voice")
+		if err != nil {
+			http.Error(w, `{"message":"no voice file"}`, http.StatusBadRequest)
+			return
+		}
+		file.Close()
+		if header.Filename != "filename" || r.FormValue("language") != "fr" {
+			http.Error(w, `{"message":"unexpected form"}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, getSuccessfulRequestJSONResponse())
+	}))
+	defer server.Close()
+
+	client := RequestClient{Token: "recast_token", Endpoint: server.URL}
+	if _, err := client.AnalyzeFile("./test/test.wav", &ReqOpts{Language: "fr"}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+}
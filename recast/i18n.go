@@ -0,0 +1,515 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PluralForm is a plural category of the CLDR plural rules
+type PluralForm string
+
+// Plural forms
+const (
+	PluralZero  PluralForm = "zero"
+	PluralOne   PluralForm = "one"
+	PluralTwo   PluralForm = "two"
+	PluralFew   PluralForm = "few"
+	PluralMany  PluralForm = "many"
+	PluralOther PluralForm = "other"
+)
+
+var pluralForms = map[PluralForm]bool{
+	PluralZero: true, PluralOne: true, PluralTwo: true,
+	PluralFew: true, PluralMany: true, PluralOther: true,
+}
+
+// PluralRule returns the plural form to use for a count
+type PluralRule func(n int) PluralForm
+
+var pluralRules = struct {
+	sync.RWMutex
+	rules map[string]PluralRule
+}{rules: map[string]PluralRule{}}
+
+// RegisterPluralRule sets the plural rule of a language
+// Rules are registered for the most common languages, the english rule is used for the other ones
+func RegisterPluralRule(language string, rule PluralRule) {
+	pluralRules.Lock()
+	defer pluralRules.Unlock()
+	pluralRules.rules[normalizeLanguage(language)] = rule
+}
+
+// PluralRuleFor returns the plural rule of a language, or of its base language
+func PluralRuleFor(language string) PluralRule {
+	pluralRules.RLock()
+	defer pluralRules.RUnlock()
+	language = normalizeLanguage(language)
+	if rule, found := pluralRules.rules[language]; found {
+		return rule
+	}
+	if rule, found := pluralRules.rules[baseLanguage(language)]; found {
+		return rule
+	}
+	return englishPlural
+}
+
+func englishPlural(n int) PluralForm {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func frenchPlural(n int) PluralForm {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func noPlural(n int) PluralForm {
+	return PluralOther
+}
+
+func russianPlural(n int) PluralForm {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return PluralOne
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func polishPlural(n int) PluralForm {
+	switch {
+	case n == 1:
+		return PluralOne
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func arabicPlural(n int) PluralForm {
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case n%100 >= 3 && n%100 <= 10:
+		return PluralFew
+	case n%100 >= 11:
+		return PluralMany
+	}
+	return PluralOther
+}
+
+func init() {
+	for _, language := range []string{"fr", "pt-br"} {
+		RegisterPluralRule(language, frenchPlural)
+	}
+	for _, language := range []string{"ja", "ko", "zh", "th", "vi", "id", "tr"} {
+		RegisterPluralRule(language, noPlural)
+	}
+	for _, language := range []string{"ru", "uk"} {
+		RegisterPluralRule(language, russianPlural)
+	}
+	RegisterPluralRule("pl", polishPlural)
+	RegisterPluralRule("ar", arabicPlural)
+}
+
+// Bundle holds the message catalogs of the languages supported by a bot
+// Messages are looked up in the requested language, then in its fallbacks,
+// its base language and the default language: fr-CA, fr, en
+//	bundle := recast.NewBundle("en")
+//	err := bundle.LoadFile("locales/fr.yaml")
+//	l := bundle.ForResponse(response)
+//	err = w.Reply(l.Text("greetings", name))
+type Bundle struct {
+	// DefaultLanguage is the last language tried when looking up a message
+	DefaultLanguage string
+
+	mu sync.RWMutex
+	// fallbacks holds the languages to try after a language, before its base language
+	fallbacks map[string][]string
+	catalogs  map[string]map[string]map[PluralForm]string
+}
+
+// NewBundle returns an empty bundle with a default language
+func NewBundle(defaultLanguage string) *Bundle {
+	return &Bundle{
+		DefaultLanguage: defaultLanguage,
+		fallbacks:       map[string][]string{},
+		catalogs:        map[string]map[string]map[PluralForm]string{},
+	}
+}
+
+// Add sets the message key of a language
+// The message is a format string for fmt.Sprintf
+func (b *Bundle) Add(language, key, message string) {
+	b.AddPlural(language, key, map[PluralForm]string{PluralOther: message})
+}
+
+// AddPlural sets the plural forms of the message key of a language
+// The other form is mandatory, it is used when the form of a count is missing
+func (b *Bundle) AddPlural(language, key string, forms map[PluralForm]string) error {
+	if _, found := forms[PluralOther]; !found {
+		return fmt.Errorf("Invalid message %s: the other form is missing", key)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	language = normalizeLanguage(language)
+	if b.catalogs == nil {
+		b.catalogs = map[string]map[string]map[PluralForm]string{}
+	}
+	if b.catalogs[language] == nil {
+		b.catalogs[language] = map[string]map[PluralForm]string{}
+	}
+	b.catalogs[language][key] = forms
+	return nil
+}
+
+// LoadFile adds the messages of a YAML or JSON catalog
+// The language is the name of the file, such as fr-CA.yaml
+func (b *Bundle) LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	language := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = b.LoadJSON(language, data)
+	} else {
+		err = b.LoadYAML(language, data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// LoadYAML adds the messages of a YAML catalog
+// Nested keys are joined with dots, and mappings of plural forms are plural messages:
+//	weather:
+//	  sunny: It is sunny in %s
+//	apples:
+//	  one: One apple
+//	  other: "%d apples"
+func (b *Bundle) LoadYAML(language string, data []byte) error {
+	catalog, err := decodeYAML(data)
+	if err != nil {
+		return err
+	}
+	return b.load(language, "", catalog)
+}
+
+// LoadJSON adds the messages of a JSON catalog, with the layout of the YAML catalogs
+func (b *Bundle) LoadJSON(language string, data []byte) error {
+	var catalog interface{}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return err
+	}
+	return b.load(language, "", catalog)
+}
+
+func (b *Bundle) load(language, prefix string, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if prefix == "" {
+			return fmt.Errorf("Invalid catalog: expected a mapping of messages")
+		}
+		b.Add(language, prefix, value)
+		return nil
+	case map[string]interface{}:
+		if forms, ok := pluralMessage(value); ok && prefix != "" {
+			return b.AddPlural(language, prefix, forms)
+		}
+		for key, item := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := b.load(language, key, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("Invalid message %s: expected a text or a mapping", prefix)
+}
+
+// pluralMessage returns the forms of a mapping holding only plural forms, including other
+func pluralMessage(value map[string]interface{}) (map[PluralForm]string, bool) {
+	if _, found := value[string(PluralOther)]; !found {
+		return nil, false
+	}
+	forms := make(map[PluralForm]string, len(value))
+	for key, item := range value {
+		message, ok := item.(string)
+		if !ok || !pluralForms[PluralForm(key)] {
+			return nil, false
+		}
+		forms[PluralForm(key)] = message
+	}
+	return forms, true
+}
+
+// Languages returns the languages having a catalog
+func (b *Bundle) Languages() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	languages := make([]string, 0, len(b.catalogs))
+	for language := range b.catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// SetFallbacks sets the languages to try after language, before its base language
+//	bundle.SetFallbacks("pt-BR", "pt-PT", "es")
+func (b *Bundle) SetFallbacks(language string, fallbacks ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fallbacks == nil {
+		b.fallbacks = map[string][]string{}
+	}
+	normalized := make([]string, len(fallbacks))
+	for i, l := range fallbacks {
+		normalized[i] = normalizeLanguage(l)
+	}
+	b.fallbacks[normalizeLanguage(language)] = normalized
+}
+
+// Chain returns the languages in which messages are looked up for language
+func (b *Bundle) Chain(language string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.chain(language)
+}
+
+func (b *Bundle) chain(language string) []string {
+	var chain []string
+	seen := map[string]bool{}
+	add := func(languages ...string) {
+		for _, l := range languages {
+			l = normalizeLanguage(l)
+			if l != "" && !seen[l] {
+				seen[l] = true
+				chain = append(chain, l)
+			}
+		}
+	}
+
+	language = normalizeLanguage(language)
+	add(language)
+	add(b.fallbacks[language]...)
+	add(baseLanguage(language), b.DefaultLanguage)
+	return chain
+}
+
+// Lookup returns the message key in the first language of the chain of language having it,
+// and the language it was found in
+func (b *Bundle) Lookup(language, key string) (forms map[PluralForm]string, found string, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range b.chain(language) {
+		if forms, ok := b.catalogs[l][key]; ok {
+			return forms, l, true
+		}
+	}
+	return nil, "", false
+}
+
+// T returns the message key in language, formatted with args
+// The key is returned if the message is not found
+func (b *Bundle) T(language, key string, args ...interface{}) string {
+	forms, _, ok := b.Lookup(language, key)
+	if !ok {
+		return key
+	}
+	return formatMessage(forms[PluralOther], args)
+}
+
+// Plural returns the plural form of the message key matching count,
+// formatted with count followed by args
+//	bundle.Plural("fr", "apples", n)
+func (b *Bundle) Plural(language, key string, count int, args ...interface{}) string {
+	forms, found, ok := b.Lookup(language, key)
+	if !ok {
+		return key
+	}
+	// the plural rule is the one of the language the message is written in
+	message, ok := forms[PluralRuleFor(found)(count)]
+	if !ok {
+		message = forms[PluralOther]
+	}
+	return formatMessage(message, append([]interface{}{count}, args...))
+}
+
+// formatMessage formats message with args, the args it does not use are ignored
+// so that the forms of a message do not have to display the same values
+func formatMessage(message string, args []interface{}) string {
+	if len(args) == 0 {
+		return message
+	}
+	if n := formatArgs(message); n < len(args) {
+		args = args[:n]
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// formatArgs returns the number of args used by a format string,
+// the highest arg index of its verbs and * widths and precisions
+func formatArgs(format string) int {
+	used, arg := 0, 0
+	consume := func() {
+		arg++
+		if arg > used {
+			used = arg
+		}
+	}
+	// index reads an explicit arg index such as [2] at format[i]
+	index := func(i int) int {
+		if i < len(format) && format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+					arg = n - 1
+				}
+				return i + end + 1
+			}
+		}
+		return i
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		// width
+		if i = index(i); i < len(format) && format[i] == '*' {
+			consume()
+			i++
+		}
+		for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+			i++
+		}
+		// precision
+		if i < len(format) && format[i] == '.' {
+			if i = index(i + 1); i < len(format) && format[i] == '*' {
+				consume()
+				i++
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		i = index(i)
+		if i < len(format) && format[i] != '%' {
+			consume()
+		}
+	}
+	return used
+}
+
+// Localizer returns the helper translating messages in language
+func (b *Bundle) Localizer(language string) Localizer {
+	return Localizer{Bundle: b, Language: language}
+}
+
+// ForResponse returns the localizer of the language detected in a response
+func (b *Bundle) ForResponse(r Response) Localizer {
+	return b.Localizer(detectedLanguage(r.Language, r.ProcessingLanguage))
+}
+
+// ForConversation returns the localizer of the language detected in a conversation
+func (b *Bundle) ForConversation(conv Conversation) Localizer {
+	return b.Localizer(detectedLanguage(conv.Language, conv.ProcessingLanguage))
+}
+
+func detectedLanguage(language, processingLanguage string) string {
+	if language != "" {
+		return language
+	}
+	return processingLanguage
+}
+
+// normalizeLanguage lowercases a language code and uses dashes as separator
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(language), "_", "-", -1))
+}
+
+// Localizer translates messages and builds components in a language
+// Buttons use the message key as value, so that the answer does not depend on the language
+type Localizer struct {
+	Bundle   *Bundle
+	Language string
+}
+
+// T returns the message key formatted with args
+func (l Localizer) T(key string, args ...interface{}) string {
+	return l.Bundle.T(l.Language, key, args...)
+}
+
+// Plural returns the plural form of the message key matching count, formatted with count and args
+func (l Localizer) Plural(key string, count int, args ...interface{}) string {
+	return l.Bundle.Plural(l.Language, key, count, args...)
+}
+
+// Text returns a text message of the message key
+func (l Localizer) Text(key string, args ...interface{}) Attachment {
+	return NewTextMessage(l.T(key, args...))
+}
+
+// QuickReplies returns quick replies titled by the message title,
+// with a button per key
+func (l Localizer) QuickReplies(title string, keys ...string) *QuickReplies {
+	q := NewQuickReplies(l.T(title))
+	for _, key := range keys {
+		q.AddButton(l.T(key), key)
+	}
+	return q
+}
+
+// Buttons returns buttons titled by the message title, with a postback button per key
+func (l Localizer) Buttons(title string, keys ...string) *Buttons {
+	b := NewButtons(l.T(title))
+	for _, key := range keys {
+		b.AddButtons(l.PostbackButton(key))
+	}
+	return b
+}
+
+// Card returns a card with the messages title and subtitle
+// An empty subtitle key is left empty
+func (l Localizer) Card(title, subtitle string) *Card {
+	if subtitle != "" {
+		subtitle = l.T(subtitle)
+	}
+	return NewCard(l.T(title), subtitle)
+}
+
+// PostbackButton returns a postback button titled by the message key, sending the key
+func (l Localizer) PostbackButton(key string) CardButton {
+	return PostbackButton(l.T(key), key)
+}
+
+// URLButton returns a button titled by the message key, opening url
+func (l Localizer) URLButton(key, url string) CardButton {
+	return URLButton(l.T(key), url)
+}
@@ -0,0 +1,169 @@
+package recast
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testCatalogEN = `
+greetings: Hello %s!
+goodbye: Bye!
+apples:
+  one: One apple
+  other: "%d apples"
+menu:
+  title: What do you want?
+  weather: Weather
+  news: News
+`
+
+const testCatalogFR = `
+greetings: Bonjour %s !
+apples:
+  one: "%d pomme"
+  other: "%d pommes"
+menu:
+  title: Que voulez-vous ?
+  weather: Météo
+`
+
+func newTestBundle(t *testing.T) *Bundle {
+	bundle := NewBundle("en")
+	if err := bundle.LoadYAML("en", []byte(testCatalogEN)); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := bundle.LoadYAML("fr", []byte(testCatalogFR)); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	return bundle
+}
+
+func TestBundleFallbacks(t *testing.T) {
+	bundle := newTestBundle(t)
+	if err := bundle.LoadFile("./test/locales/fr-CA.yaml"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	tests := []struct {
+		language, key, expected string
+	}{
+		{"fr-CA", "greetings", "Allô Jean!"},
+		{"fr_FR", "greetings", "Bonjour Jean !"},
+		{"fr-CA", "goodbye", "Bye!"},
+		{"de", "greetings", "Hello Jean!"},
+		{"fr", "unknown", "unknown"},
+		{"fr", "menu.weather", "Météo"},
+		{"fr", "menu.news", "News"},
+	}
+	for _, test := range tests {
+		var result string
+		if test.key == "greetings" {
+			result = bundle.T(test.language, test.key, "Jean")
+		} else {
+			result = bundle.T(test.language, test.key)
+		}
+		if result != test.expected {
+			t.Fatalf("%s %s: expected %q, but instead got %q", test.language, test.key, test.expected, result)
+		}
+	}
+
+	bundle.SetFallbacks("pt-BR", "fr")
+	if chain := bundle.Chain("pt-BR"); !reflect.DeepEqual(chain, []string{"pt-br", "fr", "pt", "en"}) {
+		t.Fatalf("Unexpected chain %v", chain)
+	}
+	if result := bundle.T("pt-BR", "greetings", "Jean"); result != "Bonjour Jean !" {
+		t.Fatalf("Expected the fallback to be used, got %q", result)
+	}
+
+	bundle.SetFallbacks("PT_br", "ES", "fr")
+	if chain := bundle.Chain("pt-BR"); !reflect.DeepEqual(chain, []string{"pt-br", "es", "fr", "pt", "en"}) || len(bundle.fallbacks) != 1 {
+		t.Fatalf("Unexpected chain %v with fallbacks %v", chain, bundle.fallbacks)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		args     []interface{}
+		expected string
+	}{
+		{"Hello %s!", []interface{}{"Jean", 2}, "Hello Jean!"},
+		{"Bye!", []interface{}{"Jean"}, "Bye!"},
+		{"100%% of %d", []interface{}{3, "unused"}, "100% of 3"},
+		{"%[2]s, %[1]s", []interface{}{"a", "b", "c"}, "b, a"},
+		{"%*d|", []interface{}{3, 7, 8}, "  7|"},
+		{"%.[2]*[1]f", []interface{}{1.5, 2, 0}, "1.50"},
+		{"%d%%!(EXTRA %s)", []interface{}{1, "kept", "dropped"}, "1%!(EXTRA kept)"},
+	}
+	for _, test := range tests {
+		if result := formatMessage(test.message, test.args); result != test.expected {
+			t.Errorf("%q: expected %q, but instead got %q", test.message, test.expected, result)
+		}
+	}
+}
+
+func TestBundlePlural(t *testing.T) {
+	bundle := newTestBundle(t)
+	tests := []struct {
+		language string
+		count    int
+		expected string
+	}{
+		{"en", 0, "0 apples"},
+		{"en", 1, "One apple"},
+		{"en", 2, "2 apples"},
+		{"fr", 0, "0 pomme"},
+		{"fr", 1, "1 pomme"},
+		{"fr", 3, "3 pommes"},
+		{"de", 1, "One apple"},
+	}
+	for _, test := range tests {
+		if result := bundle.Plural(test.language, "apples", test.count); result != test.expected {
+			t.Fatalf("%s %d: expected %q, but instead got %q", test.language, test.count, test.expected, result)
+		}
+	}
+
+	if result := bundle.T("en", "goodbye", "Jean"); result != "Bye!" {
+		t.Fatalf("Expected unused args to be ignored, got %q", result)
+	}
+
+	if err := bundle.AddPlural("en", "pears", map[PluralForm]string{PluralOne: "One pear"}); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if result := bundle.T("en", "pears"); result != "pears" {
+		t.Fatalf("A message with no other form should not be added, got %q", result)
+	}
+
+	rule := PluralRuleFor("ru")
+	for n, expected := range map[int]PluralForm{1: PluralOne, 3: PluralFew, 5: PluralMany, 11: PluralMany, 21: PluralOne} {
+		if form := rule(n); form != expected {
+			t.Fatalf("ru %d: expected %s, but instead got %s", n, expected, form)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	bundle := newTestBundle(t)
+	l := bundle.ForResponse(Response{Language: "fr", ProcessingLanguage: "en"})
+	if l.Language != "fr" {
+		t.Fatalf("Expected the detected language to be used, got %s", l.Language)
+	}
+
+	if text := l.Text("greetings", "Jean"); text.Content != "Bonjour Jean !" {
+		t.Fatalf("Unexpected text %+v", text)
+	}
+
+	expected := NewQuickReplies("Que voulez-vous ?").AddButton("Météo", "menu.weather").AddButton("News", "menu.news")
+	if q := l.QuickReplies("menu.title", "menu.weather", "menu.news"); !reflect.DeepEqual(q, expected) {
+		t.Fatalf("Expected %+v, but instead got %+v", expected, q)
+	}
+
+	buttons := l.Buttons("menu.title", "menu.weather")
+	if b := buttons.Content.Buttons[0]; b.Title != "Météo" || b.Value != "menu.weather" || b.Kind() != ButtonPostback {
+		t.Fatalf("Unexpected button %+v", b)
+	}
+
+	if l := bundle.ForConversation(Conversation{ProcessingLanguage: "en"}); l.T("menu.weather") != "Weather" {
+		t.Fatalf("Expected the processing language to be used")
+	}
+}
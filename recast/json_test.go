@@ -162,6 +162,53 @@ func getValidConversationMessage() string {
 }`
 }
 
+func getValidSlackConversationMessage() string {
+	return `
+{
+	"message": {
+		"participant": "ab3c9d1e-5c27-4d2d-8d8a-7a4f1c7bb5a1",
+		"conversation": "5b1c2a66-7e45-4c3a-9d86-2d1f0f7c4b10",
+		"attachment": {
+			"content": "Hi there",
+			"type": "text"
+		},
+		"receivedAt": "2017-04-02T10:12:30.000Z",
+		"isActive": true,
+		"_id": "0e5a3a77-96f4-4f8e-9a67-4c3f3c1c2b6e"
+	},
+	"chatId": "D4F6A0B3K",
+	"senderId": "U2147483697",
+	"channelType": "slack"
+}`
+}
+
+func getConnectorConversationJSONResponse() string {
+	return `{
+	"results": {
+		"id": "c1",
+		"channel": "ch1",
+		"connector": "con1",
+		"chatId": 123456,
+		"isActive": true,
+		"createdAt": "2017-03-20T21:58:50.000Z",
+		"participants": [
+			{"id": "p1", "isBot": true, "senderId": "bot"},
+			{"id": "p2", "isBot": false, "senderId": 123467}
+		],
+		"messages": [
+			{
+				"_id": "m1",
+				"conversation": "c1",
+				"participant": "p2",
+				"attachment": {"type": "text", "content": "Hello"},
+				"receivedAt": "2017-03-20T21:58:52.346Z"
+			}
+		]
+	},
+	"message": "Conversation rendered with success"
+}`
+}
+
 func getSuccessfulDialogJSONResponse() string {
 	return `{
 	"message": "OK",
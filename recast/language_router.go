@@ -0,0 +1,151 @@
+package recast
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoRoute is returned when a router has no route for the detected language nor a default route
+var ErrNoRoute = errors.New("No route matches the language")
+
+// Detection sources of a Route
+const (
+	DetectionAPI     = "api"
+	DetectionLocal   = "local"
+	DetectionDefault = "default"
+)
+
+// Route is the client a text was routed to
+type Route struct {
+	// Language is the language of the route
+	Language string
+	// Detected is the language detected in the text, empty if it was not detected
+	Detected string
+	// Source tells how the language was detected: DetectionAPI, DetectionLocal or DetectionDefault
+	Source string
+	// Client is the client of the route
+	Client *RequestClient
+}
+
+// LanguageRouter sends texts to the client configured for their language
+// The language is detected with the API, by analyzing the text with no language set,
+// or locally with Detector when the API fails or LocalOnly is set
+//	router := recast.LanguageRouter{
+//		Routes: map[string]*recast.RequestClient{
+//			"en": {Token: "EN_TOKEN"},
+//			"fr": {Token: "FR_TOKEN"},
+//		},
+//		Default: "en",
+//	}
+//	response, route, err := router.AnalyzeText("Bonjour !")
+type LanguageRouter struct {
+	// Routes holds the client of each language, the language of a client is set by the router
+	Routes map[string]*RequestClient
+	// Default is the language of the route used when the detected language has no route
+	Default string
+	// DetectionClient analyzes the texts to detect their language, the default route is used if nil
+	DetectionClient *RequestClient
+	// Detector detects the language locally, a NGramDetector is used if nil
+	Detector LanguageDetector
+	// LocalOnly disables the detection with the API
+	LocalOnly bool
+
+	once     sync.Once
+	detector LanguageDetector
+}
+
+// Route detects the language of text and returns the route to use
+func (r *LanguageRouter) Route(text string) (Route, error) {
+	route, _, err := r.route(text)
+	return route, err
+}
+
+// AnalyzeText detects the language of text and analyzes it with the client of the language
+// The response of the detection is reused when it was processed in the language of the route
+func (r *LanguageRouter) AnalyzeText(text string) (Response, Route, error) {
+	route, detection, err := r.route(text)
+	if err != nil {
+		return Response{}, route, err
+	}
+	if detection != nil && route.Client == r.detectionClient() && detection.ProcessingLanguage == route.Language {
+		return *detection, route, nil
+	}
+
+	response, err := route.Client.AnalyzeText(text, &ReqOpts{Language: route.Language})
+	return response, route, err
+}
+
+// DialogText detects the language of text and sends it to the bot of the language
+// opts can be nil, its language is replaced by the language of the route
+func (r *LanguageRouter) DialogText(text string, opts *DialogOpts) (Dialog, Route, error) {
+	route, _, err := r.route(text)
+	if err != nil {
+		return Dialog{}, route, err
+	}
+
+	routed := DialogOpts{}
+	if opts != nil {
+		routed = *opts
+	}
+	routed.Language = route.Language
+	dialog, err := route.Client.dialogText(context.Background(), text, &routed)
+	return dialog, route, err
+}
+
+// route detects the language of text, and returns the response of the API detection if any
+func (r *LanguageRouter) route(text string) (Route, *Response, error) {
+	var detection *Response
+	route := Route{Source: DetectionDefault}
+
+	if client := r.detectionClient(); !r.LocalOnly && client != nil {
+		// the API detects the language when none is set
+		detector := *client
+		detector.Language = ""
+		response, err := detector.AnalyzeText(text, nil)
+		if err == nil && response.Language != "" {
+			detection = &response
+			route.Detected, route.Source = response.Language, DetectionAPI
+		}
+	}
+	if route.Detected == "" {
+		if language, _, err := r.localDetector().DetectLanguage(text); err == nil {
+			route.Detected, route.Source = language, DetectionLocal
+		}
+	}
+
+	route.Language = r.routeLanguage(route.Detected)
+	if route.Language == "" {
+		return route, detection, ErrNoRoute
+	}
+	route.Client = r.Routes[route.Language]
+	return route, detection, nil
+}
+
+// routeLanguage returns the language of the route of the detected language,
+// its base language or the default language
+func (r *LanguageRouter) routeLanguage(detected string) string {
+	for _, language := range []string{detected, baseLanguage(detected), r.Default} {
+		if client, found := r.Routes[language]; found && client != nil && language != "" {
+			return language
+		}
+	}
+	return ""
+}
+
+func (r *LanguageRouter) detectionClient() *RequestClient {
+	if r.DetectionClient != nil {
+		return r.DetectionClient
+	}
+	return r.Routes[r.Default]
+}
+
+func (r *LanguageRouter) localDetector() LanguageDetector {
+	if r.Detector != nil {
+		return r.Detector
+	}
+	r.once.Do(func() {
+		r.detector = NewNGramDetector()
+	})
+	return r.detector
+}
@@ -0,0 +1,117 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type routedRequest struct {
+	Token    string
+	Language string
+}
+
+// newLanguageServer detects french texts starting with Bonjour, and processes the others in english
+func newLanguageServer(requests *[]routedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form requestForms
+		json.NewDecoder(r.Body).Decode(&form)
+		*requests = append(*requests, routedRequest{r.Header.Get("Authorization"), form.Language})
+
+		detected := "en"
+		if len(form.Text) >= 7 && form.Text[:7] == "Bonjour" {
+			detected = "fr"
+		}
+		if form.Text == "Hola" {
+			detected = "es"
+		}
+		processing := form.Language
+		if processing == "" {
+			processing = detected
+		}
+		fmt.Fprintf(w, `{"results":{"source":%q,"language":%q,"processing_language":%q},"message":"Requests rendered with success"}`, form.Text, detected, processing)
+	}))
+}
+
+func TestLanguageRouter(t *testing.T) {
+	var requests []routedRequest
+	server := newLanguageServer(&requests)
+	defer server.Close()
+
+	router := LanguageRouter{
+		Routes: map[string]*RequestClient{
+			"en": {Token: "en_token", Endpoint: server.URL},
+			"fr": {Token: "fr_token", Endpoint: server.URL},
+		},
+		Default: "en",
+	}
+
+	tests := []struct {
+		text     string
+		route    Route
+		requests []routedRequest
+	}{
+		// the detection response is reused
+		{"Hello", Route{Language: "en", Detected: "en", Source: DetectionAPI}, []routedRequest{{"Token en_token", ""}}},
+		{"Bonjour", Route{Language: "fr", Detected: "fr", Source: DetectionAPI}, []routedRequest{{"Token en_token", ""}, {"Token fr_token", "fr"}}},
+		// there is no route for spanish, the text is processed again by the default route
+		{"Hola", Route{Language: "en", Detected: "es", Source: DetectionAPI}, []routedRequest{{"Token en_token", ""}, {"Token en_token", "en"}}},
+	}
+	for _, test := range tests {
+		requests = nil
+		response, route, err := router.AnalyzeText(test.text)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		test.route.Client = router.Routes[test.route.Language]
+		if route != test.route {
+			t.Fatalf("%s: expected route %+v, but instead got %+v", test.text, test.route, route)
+		}
+		if response.Source != test.text || response.ProcessingLanguage != test.route.Language {
+			t.Fatalf("%s: unexpected response %+v", test.text, response)
+		}
+		if fmt.Sprint(requests) != fmt.Sprint(test.requests) {
+			t.Fatalf("%s: expected requests %v, but instead got %v", test.text, test.requests, requests)
+		}
+	}
+}
+
+func TestLanguageRouterLocalDetection(t *testing.T) {
+	var requests []routedRequest
+	server := newLanguageServer(&requests)
+	defer server.Close()
+
+	router := LanguageRouter{
+		Routes: map[string]*RequestClient{
+			"en": {Token: "en_token", Endpoint: server.URL},
+			"fr": {Token: "fr_token", Endpoint: server.URL},
+		},
+		Default:   "en",
+		LocalOnly: true,
+	}
+	route, err := router.Route("Quel temps fait-il à Paris ?")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if route.Language != "fr" || route.Source != DetectionLocal || len(requests) != 0 {
+		t.Fatalf("Expected the text to be detected locally, got %+v", route)
+	}
+
+	// the local detector is used when the API fails
+	router.LocalOnly = false
+	router.DetectionClient = &RequestClient{Endpoint: server.URL}
+	route, err = router.Route("Quel temps fait-il à Paris ?")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if route.Language != "fr" || route.Source != DetectionLocal {
+		t.Fatalf("Expected the text to be detected locally, got %+v", route)
+	}
+
+	router.Default = ""
+	if _, err := router.Route("Was ist das?"); err != ErrNoRoute {
+		t.Fatalf("Expected ErrNoRoute, but instead got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package recast
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrLanguageNotDetected is returned when the language of a text cannot be detected
+var ErrLanguageNotDetected = errors.New("The language of the text is not detected")
+
+// LanguageDetector detects the language of a text
+type LanguageDetector interface {
+	// DetectLanguage returns the language of text and the confidence of the detection, between 0 and 1
+	DetectLanguage(text string) (language string, confidence float64, err error)
+}
+
+// ngramSamples are the texts the built-in profiles of the NGramDetector are computed from
+var ngramSamples = map[string]string{
+	"en": `Hello, how are you today? I would like to know what the weather is like in London this week.
+Can you help me book a table for two people tonight? Thank you very much, that is all I need.
+Where is the nearest station and when does the next train leave? I want to talk to someone about my order.
+What time is it? The price of the ticket is too high, could you find something cheaper for me please?
+Tomorrow it will be cold and rainy, so take your umbrella. I need to cancel my subscription because I am moving to another city.`,
+	"fr": `Bonjour, comment allez-vous aujourd'hui ? Je voudrais savoir quel temps il fait à Paris cette semaine.
+Pouvez-vous m'aider à réserver une table pour deux personnes ce soir ? Merci beaucoup, c'est tout ce dont j'ai besoin.
+Où est la gare la plus proche et quand part le prochain train ? Je veux parler à quelqu'un de ma commande.
+Quelle heure est-il ? Le prix du billet est trop élevé, pourriez-vous me trouver quelque chose de moins cher s'il vous plaît ?
+Demain il fera froid et il pleuvra, alors prenez votre parapluie. Je dois annuler mon abonnement parce que je déménage dans une autre ville.`,
+	"es": `Hola, ¿cómo estás hoy? Me gustaría saber qué tiempo hace en Madrid esta semana.
+¿Puedes ayudarme a reservar una mesa para dos personas esta noche? Muchas gracias, eso es todo lo que necesito.
+¿Dónde está la estación más cercana y cuándo sale el próximo tren? Quiero hablar con alguien sobre mi pedido.
+¿Qué hora es? El precio del billete es demasiado alto, ¿podrías encontrarme algo más barato por favor?
+Mañana hará frío y lloverá, así que lleva tu paraguas. Necesito cancelar mi suscripción porque me mudo a otra ciudad.`,
+	"de": `Hallo, wie geht es dir heute? Ich möchte wissen, wie das Wetter diese Woche in Berlin ist.
+Kannst du mir helfen, heute Abend einen Tisch für zwei Personen zu reservieren? Vielen Dank, das ist alles, was ich brauche.
+Wo ist der nächste Bahnhof und wann fährt der nächste Zug? Ich möchte mit jemandem über meine Bestellung sprechen.
+Wie spät ist es? Der Preis der Fahrkarte ist zu hoch, könntest du bitte etwas Günstigeres für mich finden?
+Morgen wird es kalt und regnerisch, also nimm deinen Regenschirm mit. Ich muss mein Abonnement kündigen, weil ich in eine andere Stadt ziehe.`,
+	"it": `Ciao, come stai oggi? Vorrei sapere che tempo fa a Roma questa settimana.
+Puoi aiutarmi a prenotare un tavolo per due persone stasera? Grazie mille, è tutto quello di cui ho bisogno.
+Dov'è la stazione più vicina e quando parte il prossimo treno? Voglio parlare con qualcuno del mio ordine.
+Che ore sono? Il prezzo del biglietto è troppo alto, potresti trovarmi qualcosa di più economico per favore?
+Domani farà freddo e pioverà, quindi prendi l'ombrello. Devo cancellare il mio abbonamento perché mi trasferisco in un'altra città.`,
+	"pt": `Olá, como você está hoje? Eu gostaria de saber como está o tempo em Lisboa esta semana.
+Você pode me ajudar a reservar uma mesa para duas pessoas esta noite? Muito obrigado, é tudo o que eu preciso.
+Onde fica a estação mais próxima e quando sai o próximo trem? Quero falar com alguém sobre o meu pedido.
+Que horas são? O preço do bilhete é muito alto, você poderia encontrar algo mais barato para mim por favor?
+Amanhã vai fazer frio e chover, então leve o seu guarda-chuva. Preciso cancelar a minha assinatura porque vou me mudar para outra cidade.`,
+	"nl": `Hallo, hoe gaat het vandaag met je? Ik wil graag weten hoe het weer deze week in Amsterdam is.
+Kun je me helpen een tafel voor twee personen te reserveren voor vanavond? Hartelijk dank, dat is alles wat ik nodig heb.
+Waar is het dichtstbijzijnde station en wanneer vertrekt de volgende trein? Ik wil met iemand praten over mijn bestelling.
+Hoe laat is het? De prijs van het kaartje is te hoog, kun je alsjeblieft iets goedkopers voor me vinden?
+Morgen wordt het koud en regenachtig, dus neem je paraplu mee. Ik moet mijn abonnement opzeggen omdat ik naar een andere stad verhuis.`,
+}
+
+// NGramDetector detects languages locally by comparing the character n-grams of texts
+// with the profiles of the languages
+// It is less accurate than the API on short texts, but does not need a request
+type NGramDetector struct {
+	mu       sync.RWMutex
+	profiles map[string]ngramProfile
+}
+
+// ngramProfile holds the normalized frequencies of the n-grams of a language
+type ngramProfile map[string]float64
+
+// NewNGramDetector returns a detector with profiles for en, fr, es, de, it, pt and nl
+func NewNGramDetector() *NGramDetector {
+	d := &NGramDetector{profiles: map[string]ngramProfile{}}
+	for language, sample := range ngramSamples {
+		d.AddLanguage(language, sample)
+	}
+	return d
+}
+
+// AddLanguage computes the profile of a language from a sample text
+// The profile replaces the existing one, longer samples give better results
+func (d *NGramDetector) AddLanguage(language, sample string) {
+	profile := newNGramProfile(sample)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.profiles == nil {
+		d.profiles = map[string]ngramProfile{}
+	}
+	d.profiles[language] = profile
+}
+
+// DetectLanguage implements LanguageDetector
+// The confidence is the cosine similarity of the text with the closest profile
+func (d *NGramDetector) DetectLanguage(text string) (string, float64, error) {
+	profile := newNGramProfile(text)
+	if len(profile) == 0 {
+		return "", 0, ErrLanguageNotDetected
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	best, bestScore := "", 0.0
+	for language, reference := range d.profiles {
+		score := 0.0
+		for ngram, weight := range profile {
+			score += weight * reference[ngram]
+		}
+		if score > bestScore || (score == bestScore && language < best) {
+			best, bestScore = language, score
+		}
+	}
+	if best == "" {
+		return "", 0, ErrLanguageNotDetected
+	}
+	return best, bestScore, nil
+}
+
+// newNGramProfile counts the 2 and 3 character n-grams of the words of text,
+// the words are padded with spaces so that their first and last letters are weighted
+func newNGramProfile(text string) ngramProfile {
+	profile := ngramProfile{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := 2; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				profile[string(runes[i:i+n])]++
+			}
+		}
+	}
+
+	// the profile is normalized, so that scores are cosine similarities
+	norm := 0.0
+	for _, count := range profile {
+		norm += count * count
+	}
+	norm = math.Sqrt(norm)
+	for ngram, count := range profile {
+		profile[ngram] = count / norm
+	}
+	return profile
+}
@@ -0,0 +1,35 @@
+package recast
+
+import (
+	"testing"
+)
+
+func TestNGramDetector(t *testing.T) {
+	detector := NewNGramDetector()
+	tests := map[string]string{
+		"What is the weather like in London tomorrow?":        "en",
+		"Quel temps fera-t-il à Paris demain ?":               "fr",
+		"¿Qué tiempo hará mañana en Madrid?":                  "es",
+		"Wie wird das Wetter morgen in Berlin?":               "de",
+		"Che tempo farà domani a Roma?":                       "it",
+		"Como vai estar o tempo amanhã em Lisboa?":            "pt",
+		"Hoe wordt het weer morgen in Amsterdam?":             "nl",
+		"I would like to talk to someone about my last order": "en",
+	}
+	for text, expected := range tests {
+		language, confidence, err := detector.DetectLanguage(text)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if language != expected {
+			t.Fatalf("%s: expected %s, but instead got %s", text, expected, language)
+		}
+		if confidence <= 0 || confidence > 1 {
+			t.Fatalf("%s: unexpected confidence %f", text, confidence)
+		}
+	}
+
+	if _, _, err := detector.DetectLanguage("42 !?"); err != ErrLanguageNotDetected {
+		t.Fatalf("Expected ErrLanguageNotDetected, but instead got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package recasttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Mode defines how a Cassette handles requests
+type Mode int
+
+const (
+	// ModeReplay serves the recorded responses without reaching the network
+	ModeReplay Mode = iota
+	// ModeRecord performs the requests and records them
+	ModeRecord
+	// ModePassthrough performs the requests without recording them
+	ModePassthrough
+)
+
+// redactedToken replaces the authorization tokens in the cassettes
+const redactedToken = "Token REDACTED"
+
+// ParseMode returns the Mode named s ("replay", "record" or "passthrough")
+// It can be used to select the mode from an environment variable
+//	mode, err := recasttest.ParseMode(os.Getenv("RECAST_CASSETTE"))
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "passthrough":
+		return ModePassthrough, nil
+	}
+	return ModeReplay, fmt.Errorf("Unknown cassette mode: %s", s)
+}
+
+// CassetteResponse is a response stored in a cassette
+type CassetteResponse struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// CassetteRequest is a request stored in a cassette
+type CassetteRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Token  string          `json:"token,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Interaction is a request and its response stored in a cassette
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// Cassette is an http.RoundTripper recording the API traffic to a JSON file
+// and replaying it offline. The authorization tokens are never written to the file.
+//	cassette, err := recasttest.NewCassette("testdata/weather.json", recasttest.ModeReplay)
+//	client := recast.RequestClient{Token: "TOKEN", Transport: cassette}
+//	response, err := client.AnalyzeText("What is the weather in London?", nil)
+//	err = cassette.Close()
+type Cassette struct {
+	Path string
+	Mode Mode
+	// Transport performs the requests in record and passthrough modes
+	// It defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette creates a cassette stored at path
+// In replay mode the cassette file is loaded and must exist
+func NewCassette(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{Path: path, Mode: mode}
+	if mode != ModeReplay {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("Invalid cassette %s: %v", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Mode == ModeReplay {
+		return c.replay(req, request)
+	}
+
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil || c.Mode != ModeRecord {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: request,
+		Response: CassetteResponse{
+			Status: resp.StatusCode,
+			Header: resp.Header,
+			Body:   jsonOrString(body),
+		},
+	})
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file
+// It does nothing unless the cassette is in record mode
+func (c *Cassette) Save() error {
+	if c.Mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.Path, data, os.FileMode(0644))
+}
+
+// Close saves the cassette
+func (c *Cassette) Close() error {
+	return c.Save()
+}
+
+func (c *Cassette) replay(req *http.Request, request CassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, interaction := range c.interactions {
+		if c.used[i] || !matchRequest(interaction.Request, request) {
+			continue
+		}
+		c.used[i] = true
+
+		body := []byte(interaction.Response.Body)
+		var s string
+		if json.Unmarshal(body, &s) == nil {
+			body = []byte(s)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("No interaction recorded in %s for %s %s", c.Path, request.Method, request.URL)
+}
+
+// recordRequest copies the request with a redacted token, the body
+// is restored so the request can still be sent
+func recordRequest(req *http.Request) (CassetteRequest, error) {
+	request := CassetteRequest{Method: req.Method, URL: req.URL.String()}
+	if req.Header.Get("Authorization") != "" {
+		request.Token = redactedToken
+	}
+	if req.Body == nil || strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		// multipart boundaries are random so the body can not be matched
+		return request, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return request, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.Body = jsonOrString(body)
+	return request, nil
+}
+
+// jsonOrString returns data if it is valid JSON, or data as a JSON string otherwise
+func jsonOrString(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	s, _ := json.Marshal(string(data))
+	return json.RawMessage(s)
+}
+
+func matchRequest(recorded, request CassetteRequest) bool {
+	if recorded.Method != request.Method || recorded.URL != request.URL {
+		return false
+	}
+	if len(recorded.Body) == 0 && len(request.Body) == 0 {
+		return true
+	}
+	var a, b interface{}
+	if json.Unmarshal(recorded.Body, &a) != nil || json.Unmarshal(request.Body, &b) != nil {
+		return bytes.Equal(recorded.Body, request.Body)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,106 @@
+package recasttest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RecastAI/SDK-Golang/recast"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	nlu := NewNLU()
+	nlu.On("Hello", Fixture{Intent: "greetings"})
+	connector := NewConnector(recast.NewConnectClient("secret_token"))
+
+	recorder, err := NewCassette(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	client := recast.RequestClient{Token: "secret_token", Endpoint: nlu.URL, Transport: recorder}
+	connect := recast.NewConnectClient("secret_token")
+	connect.Endpoint = connector.URL
+	connect.Transport = recorder
+
+	recorded, err := client.AnalyzeText("Hello", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err = connect.SendMessage("conversation_id", recast.NewTextMessage("Hi")); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err = recorder.Close(); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	nlu.Close()
+	connector.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "secret_token") || !strings.Contains(string(data), "REDACTED") {
+		t.Fatalf("The token should be redacted: %s", data)
+	}
+
+	player, err := NewCassette(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	client.Transport = player
+	connect.Transport = player
+
+	replayed, err := client.AnalyzeText("Hello", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if replayed.UUID != recorded.UUID || replayed.Intents[0].Slug != "greetings" {
+		t.Fatalf("Unexpected replayed response: %+v", replayed)
+	}
+	if err = connect.SendMessage("conversation_id", recast.NewTextMessage("Hi")); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	if _, err = client.AnalyzeText("Hello", nil); err == nil {
+		t.Fatal("Each interaction should only be replayed once")
+	}
+	if _, err = client.AnalyzeText("Goodbye", nil); err == nil {
+		t.Fatal("Unrecorded requests should fail")
+	}
+}
+
+func TestCassettePassthrough(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+
+	cassette, _ := NewCassette(filepath.Join(os.TempDir(), "unused.json"), ModePassthrough)
+	client := recast.RequestClient{Token: "recast_token", Endpoint: nlu.URL, Transport: cassette}
+	if _, err := client.AnalyzeText("Hello", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(cassette.interactions) != 0 {
+		t.Fatal("Passthrough mode should not record")
+	}
+
+	if _, err := NewCassette(filepath.Join(os.TempDir(), "missing-cassette.json"), ModeReplay); err == nil {
+		t.Fatal("Replay mode should fail without cassette file")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for s, expected := range map[string]Mode{"": ModeReplay, "record": ModeRecord, "Passthrough": ModePassthrough} {
+		mode, err := ParseMode(s)
+		if err != nil || mode != expected {
+			t.Errorf("Unexpected mode %d for %s (err: %+v)", mode, s, err)
+		}
+	}
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Error("Expected err not to be nil, but instead got nil")
+	}
+}
@@ -0,0 +1,334 @@
+// Package recasttest provides utilities to test bots built with the recast package
+// without reaching the Recast.AI API
+package recasttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RecastAI/SDK-Golang/recast"
+)
+
+const connectPath = "/connect/v1/"
+
+// DefaultTimeout is the time the assertions wait for the bot to reply
+var DefaultTimeout = time.Second
+
+// SentMessage is a message sent by the bot to the connector
+type SentMessage struct {
+	ConversationID string
+	Token          string
+	Content        json.RawMessage
+	matched        bool
+}
+
+// Connector emulates the Recast.AI connector
+// It posts webhook payloads to the bot and captures the messages the bot sends back
+//	client := recast.NewConnectClient("TOKEN")
+//	client.UseHandler(bot)
+//	connector := recasttest.NewConnector(client)
+//	defer connector.Close()
+//	client.Endpoint = connector.URL
+//
+//	connector.SendText("CONVERSATION_ID", "Hello")
+//	err := connector.ExpectReply("CONVERSATION_ID", recast.NewTextMessage("Hi!"))
+type Connector struct {
+	// URL is the location of the emulated API, to be set as ConnectClient.Endpoint
+	URL string
+	// ChannelType is sent with every webhook payload, it defaults to webchat
+	ChannelType string
+	// Timeout is the time the assertions wait for the bot, it defaults to DefaultTimeout
+	Timeout time.Duration
+
+	bot    http.Handler
+	server *httptest.Server
+
+	mu         sync.Mutex
+	replies    []*SentMessage
+	broadcasts []*SentMessage
+	typing     map[string]bool
+	count      int
+}
+
+// NewConnector starts a connector emulator delivering messages to bot
+// The caller should call Close when finished, to shut it down
+func NewConnector(bot http.Handler) *Connector {
+	c := &Connector{
+		ChannelType: recast.ChannelWebchat,
+		Timeout:     DefaultTimeout,
+		bot:         bot,
+		typing:      map[string]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(connectPath+"conversations/", c.serveConversation)
+	mux.HandleFunc(connectPath+"messages/", c.serveBroadcast)
+	c.server = httptest.NewServer(mux)
+	c.URL = c.server.URL
+	return c
+}
+
+// Close shuts down the emulator
+func (c *Connector) Close() {
+	c.server.Close()
+}
+
+// Send delivers an attachment to the bot as if a user of conversationID sent it
+// It returns an error if the bot does not accept the webhook
+func (c *Connector) Send(conversationID, attachmentType string, content interface{}) error {
+	c.mu.Lock()
+	c.count++
+	id := c.count
+	c.mu.Unlock()
+
+	payload := map[string]interface{}{
+		"message": map[string]interface{}{
+			"_id":          fmt.Sprintf("message-%d", id),
+			"conversation": conversationID,
+			"participant":  "participant-" + conversationID,
+			"attachment": map[string]interface{}{
+				"type":    attachmentType,
+				"content": content,
+			},
+			"receivedAt": time.Now().UTC().Format(time.RFC3339Nano),
+			"isActive":   true,
+		},
+		"senderId":    "sender-" + conversationID,
+		"chatId":      "chat-" + conversationID,
+		"channelType": c.ChannelType,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	c.bot.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		return fmt.Errorf("Webhook failed (%d): %s", rr.Code, rr.Body.String())
+	}
+	return nil
+}
+
+// SendText delivers a text message to the bot
+func (c *Connector) SendText(conversationID, text string) error {
+	return c.Send(conversationID, "text", text)
+}
+
+// Replies returns the messages sent by the bot to conversationID
+func (c *Connector) Replies(conversationID string) []SentMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var replies []SentMessage
+	for _, r := range c.replies {
+		if r.ConversationID == conversationID {
+			replies = append(replies, *r)
+		}
+	}
+	return replies
+}
+
+// Broadcasts returns the messages broadcast by the bot
+func (c *Connector) Broadcasts() []SentMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	broadcasts := make([]SentMessage, 0, len(c.broadcasts))
+	for _, b := range c.broadcasts {
+		broadcasts = append(broadcasts, *b)
+	}
+	return broadcasts
+}
+
+// IsTyping returns whether or not the bot shows the typing indicator in conversationID
+func (c *Connector) IsTyping(conversationID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.typing[conversationID]
+}
+
+// ExpectReply waits for the bot to send component to conversationID
+// Each reply can only match one expectation, so the same component
+// can be expected as many times as it is sent
+func (c *Connector) ExpectReply(conversationID string, component recast.Component) error {
+	return c.expect(component, func() []*SentMessage {
+		var replies []*SentMessage
+		for _, r := range c.replies {
+			if r.ConversationID == conversationID {
+				replies = append(replies, r)
+			}
+		}
+		return replies
+	}, "reply in conversation "+conversationID)
+}
+
+// ExpectBroadcast waits for the bot to broadcast component
+func (c *Connector) ExpectBroadcast(component recast.Component) error {
+	return c.expect(component, func() []*SentMessage {
+		return c.broadcasts
+	}, "broadcast")
+}
+
+// ExpectNoReply waits for Timeout and returns an error if the bot replied to conversationID
+// with a message that was not matched by an expectation
+func (c *Connector) ExpectNoReply(conversationID string) error {
+	time.Sleep(c.timeout())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range c.replies {
+		if r.ConversationID == conversationID && !r.matched {
+			return fmt.Errorf("Unexpected reply in conversation %s: %s", conversationID, r.Content)
+		}
+	}
+	return nil
+}
+
+func (c *Connector) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (c *Connector) expect(component recast.Component, candidates func() []*SentMessage, what string) error {
+	expected, err := normalizeJSON(component)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(c.timeout())
+	for {
+		c.mu.Lock()
+		var received []string
+		for _, m := range candidates() {
+			if m.matched {
+				continue
+			}
+			var got interface{}
+			if json.Unmarshal(m.Content, &got) == nil && reflect.DeepEqual(got, expected) {
+				m.matched = true
+				c.mu.Unlock()
+				return nil
+			}
+			received = append(received, string(m.Content))
+		}
+		c.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			want, _ := json.Marshal(component)
+			return fmt.Errorf("Expected %s %s, but instead got [%s]", what, want, strings.Join(received, ", "))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+var errInvalidPayload = errors.New("Invalid messages payload")
+
+func readMessages(r *http.Request) ([]json.RawMessage, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.Messages) == 0 {
+		return nil, errInvalidPayload
+	}
+	return payload.Messages, nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	data, _ := json.Marshal(map[string]interface{}{"results": nil, "message": message})
+	w.Write(data)
+}
+
+// serveConversation handles POST /conversations/:id/messages and POST /conversations/:id/typing
+func (c *Connector) serveConversation(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, connectPath+"conversations/"), "/")
+	if r.Method != http.MethodPost || len(parts) != 2 || parts[0] == "" {
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	conversationID := parts[0]
+
+	switch parts[1] {
+	case "messages":
+		messages, err := readMessages(r)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.mu.Lock()
+		for _, m := range messages {
+			c.replies = append(c.replies, &SentMessage{
+				ConversationID: conversationID,
+				Token:          r.Header.Get("Authorization"),
+				Content:        m,
+			})
+		}
+		c.typing[conversationID] = false
+		c.mu.Unlock()
+		writeResponse(w, http.StatusCreated, "Messages successfully posted")
+	case "typing":
+		var payload struct {
+			Typing bool `json:"typing"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.mu.Lock()
+		c.typing[conversationID] = payload.Typing
+		c.mu.Unlock()
+		writeResponse(w, http.StatusOK, "Typing indicator updated")
+	default:
+		writeResponse(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// serveBroadcast handles POST /messages
+func (c *Connector) serveBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	messages, err := readMessages(r)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.mu.Lock()
+	for _, m := range messages {
+		c.broadcasts = append(c.broadcasts, &SentMessage{
+			Token:   r.Header.Get("Authorization"),
+			Content: m,
+		})
+	}
+	c.mu.Unlock()
+	writeResponse(w, http.StatusCreated, "Messages successfully posted")
+}
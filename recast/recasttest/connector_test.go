@@ -0,0 +1,95 @@
+package recasttest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RecastAI/SDK-Golang/recast"
+)
+
+func newBot() *recast.ConnectClient {
+	client := recast.NewConnectClient("recast_token")
+	client.UseHandler(recast.MessageHandlerFunc(func(w recast.MessageWriter, m recast.Message) {
+		switch content := m.Content.(type) {
+		case *recast.InboundText:
+			if content.Text == "broadcast" {
+				w.Broadcast(recast.NewTextMessage("Hello everyone"))
+				return
+			}
+			w.ReplyText("You said %s", content.Text)
+		case *recast.InboundPostback:
+			w.Reply(recast.NewQuickReplies("Sure?").AddButton("Yes", "yes").AddButton("No", "no"))
+		}
+	}))
+	return client
+}
+
+func TestConnectorExpectReply(t *testing.T) {
+	bot := newBot()
+	connector := NewConnector(bot)
+	defer connector.Close()
+	bot.Endpoint = connector.URL
+	connector.Timeout = 100 * time.Millisecond
+
+	if err := connector.SendText("conversation_id", "Hello"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if err := connector.ExpectReply("conversation_id", recast.NewTextMessage("You said Hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	connector.Send("other_conversation", "postback", "BUY")
+	expected := recast.NewQuickReplies("Sure?").AddButton("Yes", "yes").AddButton("No", "no")
+	if err := connector.ExpectReply("other_conversation", expected); err != nil {
+		t.Fatal(err)
+	}
+
+	replies := connector.Replies("conversation_id")
+	if len(replies) != 1 || replies[0].Token != "Token recast_token" {
+		t.Fatalf("Unexpected replies: %+v", replies)
+	}
+
+	if err := connector.ExpectReply("conversation_id", recast.NewTextMessage("You said Hello")); err == nil {
+		t.Fatal("A reply should only match one expectation")
+	}
+	if err := connector.ExpectNoReply("conversation_id"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnectorExpectBroadcast(t *testing.T) {
+	bot := newBot()
+	connector := NewConnector(bot)
+	defer connector.Close()
+	bot.Endpoint = connector.URL
+	connector.Timeout = 100 * time.Millisecond
+
+	connector.SendText("conversation_id", "broadcast")
+	if err := connector.ExpectBroadcast(recast.NewTextMessage("Hello everyone")); err != nil {
+		t.Fatal(err)
+	}
+	if len(connector.Broadcasts()) != 1 {
+		t.Fatalf("Unexpected broadcasts: %+v", connector.Broadcasts())
+	}
+	if err := connector.ExpectNoReply("conversation_id"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnectorTyping(t *testing.T) {
+	client := recast.NewConnectClient("recast_token")
+	connector := NewConnector(client)
+	defer connector.Close()
+	client.Endpoint = connector.URL
+
+	if err := client.SendTyping("conversation_id", true); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if !connector.IsTyping("conversation_id") {
+		t.Fatal("The bot should be typing")
+	}
+	client.SendMessage("conversation_id", recast.NewTextMessage("Done"))
+	if connector.IsTyping("conversation_id") {
+		t.Fatal("The bot should not be typing after a reply")
+	}
+}
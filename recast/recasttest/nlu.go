@@ -0,0 +1,485 @@
+package recasttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RecastAI/SDK-Golang/recast"
+)
+
+// Fixture describes how the fake API understands a sentence
+type Fixture struct {
+	Intent     string
+	Confidence float64
+	// Entities are rendered as is in the response, keyed by entity name
+	//	Entities: map[string][]interface{}{
+	//		"location": {map[string]interface{}{"raw": "Paris", "formatted": "Paris, France"}},
+	//	}
+	Entities  map[string][]interface{}
+	Sentiment string
+	Act       string
+	Type      string
+	Language  string
+	// Replies and Action are returned by the converse endpoint
+	Replies []string
+	Action  string
+	// Memory is merged into the conversation memory by the converse and dialog endpoints
+	Memory map[string]interface{}
+	// Messages are returned by the dialog endpoint
+	Messages []recast.Component
+	// Status and Error make the API fail with this status code and message
+	Status int
+	Error  string
+}
+
+// RecordedRequest is a request received by the fake API
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Token  string
+	Body   json.RawMessage
+}
+
+type conversationState struct {
+	language string
+	memory   map[string]interface{}
+}
+
+// NLU is a fake Recast.AI API implementing the request, converse and dialog endpoints
+// Sentences are understood according to the fixtures registered with On
+//	nlu := recasttest.NewNLU()
+//	defer nlu.Close()
+//	nlu.On("hello", recasttest.Fixture{Intent: "greetings", Replies: []string{"Hi!"}})
+//
+//	client := recast.RequestClient{Token: "TOKEN", Endpoint: nlu.URL}
+//	response, err := client.AnalyzeText("Hello", nil)
+type NLU struct {
+	// URL is the location of the fake API, to be set as RequestClient.Endpoint
+	URL string
+	// Token is the only token accepted by the API when set
+	Token string
+	// Default is used for sentences without fixture
+	Default Fixture
+	// Latency is added before every response
+	Latency time.Duration
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	fixtures      map[string]Fixture
+	failures      []Fixture
+	requests      []RecordedRequest
+	conversations map[string]*conversationState
+	count         int
+}
+
+// NewNLU starts a fake Recast.AI API
+// The caller should call Close when finished, to shut it down
+func NewNLU() *NLU {
+	n := &NLU{
+		Default:       Fixture{Sentiment: recast.SentimentNeutral, Act: recast.ActAssert},
+		fixtures:      map[string]Fixture{},
+		conversations: map[string]*conversationState{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/request/", n.serveRequest)
+	mux.HandleFunc("/v2/converse/", n.serveConverse)
+	mux.HandleFunc("/build/v1/dialog", n.serveDialog)
+	mux.HandleFunc("/build/v1/users/", n.serveConversationState)
+	n.server = httptest.NewServer(mux)
+	n.URL = n.server.URL
+	return n
+}
+
+// Close shuts down the fake API
+func (n *NLU) Close() {
+	n.server.Close()
+}
+
+// On registers the fixture used when text is received
+// Texts are matched without case and surrounding spaces
+func (n *NLU) On(text string, f Fixture) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fixtures[normalizeText(text)] = f
+}
+
+// FailNext makes the next request fail with status and message
+func (n *NLU) FailNext(status int, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures = append(n.failures, Fixture{Status: status, Error: message})
+}
+
+// Requests returns the requests received by the fake API
+func (n *NLU) Requests() []RecordedRequest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]RecordedRequest(nil), n.requests...)
+}
+
+// Memory returns the memory of a conversation, identified by its
+// conversation token or dialog conversation ID
+func (n *NLU) Memory(conversation string) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	state, ok := n.conversations[conversation]
+	if !ok {
+		return nil
+	}
+	memory := map[string]interface{}{}
+	for k, v := range state.memory {
+		memory[k] = v
+	}
+	return memory
+}
+
+func normalizeText(text string) string {
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+func (n *NLU) fixture(text string) Fixture {
+	if f, ok := n.fixtures[normalizeText(text)]; ok {
+		return f
+	}
+	return n.Default
+}
+
+// record stores the request and returns the pending failure, if any
+func (n *NLU) record(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if n.Latency > 0 {
+		time.Sleep(n.Latency)
+	}
+
+	var body []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil || r.MultipartForm == nil {
+			writeResponse(w, http.StatusBadRequest, "Invalid multipart form")
+			return nil, false
+		}
+		fields := map[string]string{}
+		for k := range r.MultipartForm.Value {
+			fields[k] = r.MultipartForm.Value[k][0]
+		}
+		body, _ = json.Marshal(fields)
+	} else {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	token := r.Header.Get("Authorization")
+	n.requests = append(n.requests, RecordedRequest{r.Method, r.URL.Path, token, body})
+
+	if n.Token != "" && token != "Token "+n.Token {
+		writeResponse(w, http.StatusUnauthorized, "Request can not be processed without a valid token")
+		return nil, false
+	}
+	if len(n.failures) > 0 {
+		failure := n.failures[0]
+		n.failures = n.failures[1:]
+		writeResponse(w, failure.Status, failure.Error)
+		return nil, false
+	}
+	return body, true
+}
+
+func writeResults(w http.ResponseWriter, results interface{}, message string) {
+	data, err := json.Marshal(map[string]interface{}{"results": results, "message": message})
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (n *NLU) nextID(prefix string) string {
+	n.count++
+	return fmt.Sprintf("%s-%d", prefix, n.count)
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// results renders the NLP part of a response
+func (n *NLU) results(text, language string, f Fixture) map[string]interface{} {
+	intents := []interface{}{}
+	if f.Intent != "" {
+		confidence := f.Confidence
+		if confidence == 0 {
+			confidence = 0.99
+		}
+		intents = append(intents, map[string]interface{}{"slug": f.Intent, "confidence": confidence})
+	}
+	entities := map[string][]interface{}{}
+	for k, v := range f.Entities {
+		entities[k] = v
+	}
+	language = orDefault(f.Language, orDefault(language, "en"))
+
+	return map[string]interface{}{
+		"uuid":                n.nextID("uuid"),
+		"source":              text,
+		"intents":             intents,
+		"act":                 orDefault(f.Act, n.Default.Act),
+		"type":                orDefault(f.Type, n.Default.Type),
+		"sentiment":           orDefault(f.Sentiment, n.Default.Sentiment),
+		"entities":            entities,
+		"language":            language,
+		"processing_language": language,
+		"version":             "2.4.0",
+		"timestamp":           time.Now().UTC().Format(time.RFC3339Nano),
+		"status":              http.StatusOK,
+	}
+}
+
+func (n *NLU) fail(w http.ResponseWriter, f Fixture) bool {
+	if f.Status == 0 || f.Status == http.StatusOK {
+		return false
+	}
+	writeResponse(w, f.Status, orDefault(f.Error, http.StatusText(f.Status)))
+	return true
+}
+
+// serveRequest handles POST /v2/request
+func (n *NLU) serveRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	body, ok := n.record(w, r)
+	if !ok {
+		return
+	}
+
+	var form struct {
+		Text     string `json:"text"`
+		Language string `json:"language"`
+	}
+	json.Unmarshal(body, &form)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f := n.fixture(form.Text)
+	if n.fail(w, f) {
+		return
+	}
+	writeResults(w, n.results(form.Text, form.Language, f), "Requests rendered with success")
+}
+
+func (n *NLU) state(id, language string) *conversationState {
+	state, ok := n.conversations[id]
+	if !ok {
+		state = &conversationState{language: language, memory: map[string]interface{}{}}
+		n.conversations[id] = state
+	}
+	if language != "" {
+		state.language = language
+	}
+	return state
+}
+
+// merge sets the variables of memory, a nil value resets the variable
+func (state *conversationState) merge(memory map[string]interface{}) {
+	for k, v := range memory {
+		if v == nil {
+			delete(state.memory, k)
+			continue
+		}
+		state.memory[k] = v
+	}
+}
+
+func (n *NLU) conversationResults(token string, state *conversationState, nlp map[string]interface{}, f Fixture) map[string]interface{} {
+	replies := f.Replies
+	if replies == nil {
+		replies = []string{}
+	}
+	nlp["conversation_token"] = token
+	nlp["replies"] = replies
+	nlp["action"] = map[string]interface{}{"slug": f.Action, "done": true, "reply": strings.Join(replies, " ")}
+	nlp["next_actions"] = []interface{}{}
+	nlp["memory"] = state.memory
+	nlp["language"] = orDefault(state.language, "en")
+	return nlp
+}
+
+// serveConverse handles POST, PUT and DELETE /v2/converse
+func (n *NLU) serveConverse(w http.ResponseWriter, r *http.Request) {
+	body, ok := n.record(w, r)
+	if !ok {
+		return
+	}
+
+	var form struct {
+		Text              string                 `json:"text"`
+		Language          string                 `json:"language"`
+		ConversationToken string                 `json:"conversation_token"`
+		Memory            map[string]interface{} `json:"memory"`
+	}
+	json.Unmarshal(body, &form)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPost:
+		f := n.fixture(form.Text)
+		if n.fail(w, f) {
+			return
+		}
+		token := form.ConversationToken
+		if token == "" {
+			token = n.nextID("conversation")
+		}
+		state := n.state(token, form.Language)
+		state.merge(form.Memory)
+		state.merge(f.Memory)
+		nlp := n.results(form.Text, state.language, f)
+		writeResults(w, n.conversationResults(token, state, nlp, f), "Converses rendered with success")
+	case http.MethodPut:
+		state, ok := n.conversations[form.ConversationToken]
+		if !ok {
+			writeResponse(w, http.StatusNotFound, "Conversation not found")
+			return
+		}
+		if form.Memory == nil {
+			state.memory = map[string]interface{}{}
+		}
+		state.merge(form.Memory)
+		nlp := n.results("", state.language, Fixture{})
+		writeResults(w, n.conversationResults(form.ConversationToken, state, nlp, Fixture{}), "Converse updated with success")
+	case http.MethodDelete:
+		token := r.URL.Query().Get("conversation_token")
+		if token == "" {
+			token = form.ConversationToken
+		}
+		delete(n.conversations, token)
+		writeResponse(w, http.StatusOK, "Converse deleted with success")
+	default:
+		writeResponse(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// serveDialog handles POST /build/v1/dialog
+func (n *NLU) serveDialog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	body, ok := n.record(w, r)
+	if !ok {
+		return
+	}
+
+	var form struct {
+		Message struct {
+			Type    string          `json:"type"`
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+		ConversationID string                 `json:"conversation_id"`
+		Language       string                 `json:"language"`
+		Memory         map[string]interface{} `json:"memory"`
+	}
+	json.Unmarshal(body, &form)
+
+	var text string
+	if json.Unmarshal(form.Message.Content, &text) != nil {
+		text = string(form.Message.Content)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f := n.fixture(text)
+	if n.fail(w, f) {
+		return
+	}
+
+	id := form.ConversationID
+	if id == "" {
+		id = n.nextID("dialog")
+	}
+	state := n.state(id, form.Language)
+	state.merge(form.Memory)
+	state.merge(f.Memory)
+
+	messages := f.Messages
+	if messages == nil {
+		messages = []recast.Component{}
+		for _, reply := range f.Replies {
+			messages = append(messages, recast.NewTextMessage(reply))
+		}
+	}
+
+	writeResults(w, map[string]interface{}{
+		"messages": messages,
+		"conversation": map[string]interface{}{
+			"id":               id,
+			"language":         orDefault(state.language, "en"),
+			"memory":           state.memory,
+			"skill":            orDefault(f.Action, "small-talk"),
+			"skill_occurences": 1,
+			"skill_stack":      []string{},
+		},
+		"nlp": n.results(text, state.language, f),
+	}, "Dialog rendered with success")
+}
+
+// serveConversationState handles GET, PUT and DELETE
+// /build/v1/users/{user}/bots/{bot}/versions/{version}/builder/conversation_states/{id}
+func (n *NLU) serveConversationState(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/conversation_states/")
+	if len(parts) != 2 || parts[1] == "" {
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	id := parts[1]
+	body, ok := n.record(w, r)
+	if !ok {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	state, found := n.conversations[id]
+	if !found {
+		writeResponse(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var form struct {
+			Memory map[string]interface{} `json:"memory"`
+		}
+		json.Unmarshal(body, &form)
+		state.memory = map[string]interface{}{}
+		state.merge(form.Memory)
+	case http.MethodDelete:
+		delete(n.conversations, id)
+		writeResponse(w, http.StatusOK, "Conversation deleted with success")
+		return
+	default:
+		writeResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	writeResults(w, map[string]interface{}{
+		"id":       id,
+		"language": orDefault(state.language, "en"),
+		"memory":   state.memory,
+	}, "Conversation rendered with success")
+}
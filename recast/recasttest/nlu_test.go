@@ -0,0 +1,169 @@
+package recasttest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RecastAI/SDK-Golang/recast"
+)
+
+func TestNLUAnalyzeText(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+	nlu.On("What is the weather in Paris?", Fixture{
+		Intent:   "weather",
+		Act:      recast.ActWhQuery,
+		Entities: map[string][]interface{}{"city": {map[string]interface{}{"raw": "Paris", "value": "paris", "confidence": 0.9}}},
+	})
+
+	client := recast.RequestClient{Token: "recast_token", Language: "en", Endpoint: nlu.URL}
+	response, err := client.AnalyzeText("what is the weather in paris?", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	intent, err := response.Intent()
+	if err != nil || intent.Slug != "weather" || !response.IsWhQuery() {
+		t.Fatalf("Unexpected response: %+v", response)
+	}
+	if len(response.CustomEntities["city"]) != 1 || response.CustomEntities["city"][0].Value != "paris" {
+		t.Fatalf("Unexpected custom entities: %+v", response.CustomEntities)
+	}
+
+	response, err = client.AnalyzeText("unknown sentence", nil)
+	if err != nil || len(response.Intents) != 0 || !response.IsNeutral() {
+		t.Fatalf("Unexpected response %+v (err: %+v)", response, err)
+	}
+
+	requests := nlu.Requests()
+	if len(requests) != 2 || requests[0].Token != "Token recast_token" || !strings.Contains(string(requests[0].Body), "weather") {
+		t.Fatalf("Unexpected requests: %+v", requests)
+	}
+}
+
+func TestNLUErrors(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+	nlu.Token = "recast_token"
+	nlu.On("boom", Fixture{Status: http.StatusInternalServerError})
+
+	client := recast.RequestClient{Token: "bad_token", Endpoint: nlu.URL}
+	if _, err := client.AnalyzeText("Hello", nil); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+
+	client.Token = "recast_token"
+	nlu.FailNext(http.StatusServiceUnavailable, "Unavailable")
+	if _, err := client.AnalyzeText("Hello", nil); err == nil || !strings.Contains(err.Error(), "Unavailable") {
+		t.Fatalf("Expected an Unavailable error, but instead got %+v", err)
+	}
+	if _, err := client.AnalyzeText("Hello", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, err := client.ConverseText("boom", nil); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+
+	resp, err := http.Post(nlu.URL+"/v2/request/", "multipart/form-data", strings.NewReader("not a form"))
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid form, but instead got %d", resp.StatusCode)
+	}
+
+	nlu.Latency = 20 * time.Millisecond
+	start := time.Now()
+	client.AnalyzeText("Hello", nil)
+	if time.Since(start) < nlu.Latency {
+		t.Fatal("The response should be delayed")
+	}
+}
+
+func TestNLUConverseMemory(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+	nlu.On("I live in Paris", Fixture{
+		Intent:  "location",
+		Replies: []string{"Nice city!"},
+		Memory:  map[string]interface{}{"city": map[string]interface{}{"raw": "Paris"}},
+	})
+	nlu.On("Hello", Fixture{Intent: "greetings", Replies: []string{"Hi!"}, Action: "greetings"})
+
+	client := recast.RequestClient{Token: "recast_token", Endpoint: nlu.URL}
+	conversation, err := client.ConverseText("Hello", nil)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if conversation.ConversationToken == "" || conversation.Replies[0] != "Hi!" || conversation.Action.Slug != "greetings" {
+		t.Fatalf("Unexpected conversation: %+v", conversation)
+	}
+
+	opts := recast.ConverseOpts{ConversationToken: conversation.ConversationToken}
+	conversation, err = client.ConverseText("I live in Paris", &opts)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if conversation.Memory["city"] == nil || nlu.Memory(conversation.ConversationToken)["city"] == nil {
+		t.Fatalf("The memory should be kept across turns: %+v", conversation.Memory)
+	}
+
+	if err := conversation.ResetMemory("city"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if conversation.Memory["city"] != nil || nlu.Memory(conversation.ConversationToken)["city"] != nil {
+		t.Fatalf("The city should be reset: %+v", conversation.Memory)
+	}
+}
+
+func TestNLUDialog(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+	nlu.On("Hello", Fixture{
+		Intent:   "greetings",
+		Messages: []recast.Component{recast.NewTextMessage("Hi!"), recast.NewQuickReplies("How are you?").AddButton("Good", "good")},
+	})
+
+	client := recast.RequestClient{Token: "recast_token", Endpoint: nlu.URL}
+	dialog, err := client.DialogText("Hello", &recast.DialogOpts{ConversationID: "dialog_id"})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if len(dialog.Messages) != 2 || dialog.DialogConversation.ID != "dialog_id" {
+		t.Fatalf("Unexpected dialog: %+v", dialog)
+	}
+	if intent, _ := dialog.Nlp.Intent(); intent.Slug != "greetings" {
+		t.Fatalf("Unexpected nlp: %+v", dialog.Nlp)
+	}
+}
+
+func TestNLUDialogConversation(t *testing.T) {
+	nlu := NewNLU()
+	defer nlu.Close()
+
+	client := recast.RequestClient{Token: "recast_token", Endpoint: nlu.URL, UserSlug: "user", BotSlug: "bot"}
+	if _, err := client.DialogText("Hello", &recast.DialogOpts{ConversationID: "dialog_id"}); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	conversation, err := client.UpdateDialogMemory("dialog_id", recast.Memory{"name": "Bob"})
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if conversation.Memory.Raw("name") != "Bob" || nlu.Memory("dialog_id")["name"] != "Bob" {
+		t.Fatalf("Unexpected conversation: %+v", conversation)
+	}
+
+	if conversation, err = client.GetDialogConversation("dialog_id"); err != nil || conversation.ID != "dialog_id" {
+		t.Fatalf("Unexpected conversation: %+v %+v", conversation, err)
+	}
+
+	if err := client.DeleteDialogConversation("dialog_id"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, err := client.GetDialogConversation("dialog_id"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
@@ -0,0 +1,369 @@
+package recast
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Format is an output format of a Renderer
+type Format int
+
+const (
+	// FormatText renders plain text, for SMS or voice channels
+	FormatText Format = iota
+	// FormatMarkdown renders Markdown
+	FormatMarkdown
+	// FormatHTML renders HTML, all the texts are escaped and only http(s),
+	// mailto and tel links are kept
+	FormatHTML
+)
+
+// Template renders a component, it is implemented by
+// both text/template and html/template templates
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// Renderer degrades components to text for the channels which cannot display them
+// Quick replies and postback buttons are rendered as numbered choices,
+// and URL buttons as links
+//	renderer := recast.Renderer{Format: recast.FormatText}
+//	text, err := renderer.Render(dialog.Messages...)
+type Renderer struct {
+	Format Format
+	// Templates replace the default rendering of the components, keyed by
+	// component type ("card", "quickReplies", ...). They are executed with the component.
+	// Use html/template for the HTML format, so the content is escaped.
+	//	renderer.Templates = map[string]recast.Template{
+	//		"card": template.Must(template.New("card").Parse("{{.Content.Title}}")),
+	//	}
+	Templates map[string]Template
+}
+
+// Render renders messages one after the other
+// Components that can not be rendered as text, such as Delay, are skipped
+func (r *Renderer) Render(messages ...Component) (string, error) {
+	var parts []string
+	numbered := 0
+	for _, message := range messages {
+		part, err := r.render(message, numbered)
+		if err != nil {
+			return "", err
+		}
+		numbered += len(messageChoices(message))
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	separator := "\n\n"
+	if r.Format == FormatHTML {
+		separator = "\n"
+	}
+	return strings.Join(parts, separator), nil
+}
+
+// RenderComponent renders a single component
+func (r *Renderer) RenderComponent(c Component) (string, error) {
+	return r.render(c, 0)
+}
+
+// render renders c, numbering its choices after the numbered first ones
+func (r *Renderer) render(c Component, numbered int) (string, error) {
+	if tmpl, ok := r.Templates[componentType(c)]; ok {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, c); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	w := &renderWriter{format: r.Format, numbered: numbered}
+	switch c := c.(type) {
+	case Attachment:
+		w.attachment(c.Type, c.Content)
+	case *Attachment:
+		w.attachment(c.Type, c.Content)
+	case *Audio:
+		w.attachment(c.Type, c.Content)
+	case *File:
+		w.attachment(c.Type, c.Content)
+	case *Card:
+		w.card(c.Content.Title, c.Content.Subtitle, c.Content.ImageURL, c.Content.Buttons)
+	case *Carousel:
+		for _, card := range c.Content {
+			w.card(card.Title, card.Subtitle, card.ImageURL, card.Buttons)
+		}
+	case *List:
+		for _, e := range c.Content.Elements {
+			w.card(e.Title, e.Subtitle, e.ImageURL, listButtons(e.Buttons))
+		}
+		w.buttons(listButtons(c.Content.Buttons))
+	case *Buttons:
+		w.paragraph(w.escape(c.Content.Title))
+		w.buttons(c.Content.Buttons)
+	case *QuickReplies:
+		w.paragraph(w.escape(c.Content.Title))
+		for _, b := range c.Content.Buttons {
+			w.choice(b.Title)
+		}
+	case *Delay, *RawComponent:
+	default:
+		return "", fmt.Errorf("Cannot render component of type %T", c)
+	}
+	return w.String(), nil
+}
+
+// RenderText renders messages as plain text with the default rendering
+func RenderText(messages ...Component) (string, error) {
+	return (&Renderer{Format: FormatText}).Render(messages...)
+}
+
+// ResolveChoice returns the value of the choice picked by the user when the
+// messages were rendered as numbered choices
+// answer can either be the number or the title of the choice
+//	value, ok := recast.ResolveChoice(lastReplies, message.Attachment.Content)
+func ResolveChoice(messages []Component, answer string) (string, bool) {
+	var choices []CardButton
+	for _, message := range messages {
+		choices = append(choices, messageChoices(message)...)
+	}
+
+	answer = strings.TrimSpace(answer)
+	if n, err := strconv.Atoi(strings.TrimSuffix(answer, ".")); err == nil {
+		if n >= 1 && n <= len(choices) {
+			return choices[n-1].Value, true
+		}
+		return "", false
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(choice.Title, answer) {
+			return choice.Value, true
+		}
+	}
+	return "", false
+}
+
+// messageChoices returns the choices of a message in the order they are numbered
+func messageChoices(c Component) []CardButton {
+	var buttons []CardButton
+	switch c := c.(type) {
+	case *Card:
+		buttons = c.Content.Buttons
+	case *Carousel:
+		for _, card := range c.Content {
+			buttons = append(buttons, card.Buttons...)
+		}
+	case *List:
+		for _, e := range c.Content.Elements {
+			buttons = append(buttons, listButtons(e.Buttons)...)
+		}
+		buttons = append(buttons, listButtons(c.Content.Buttons)...)
+	case *Buttons:
+		buttons = c.Content.Buttons
+	case *QuickReplies:
+		for _, b := range c.Content.Buttons {
+			buttons = append(buttons, PostbackButton(b.Title, b.Value))
+		}
+	}
+
+	var choices []CardButton
+	for _, b := range buttons {
+		if b.Kind() == ButtonPostback {
+			choices = append(choices, b)
+		}
+	}
+	return choices
+}
+
+// componentType returns the type of a component as encoded in JSON
+// The types of the custom components are looked up in the registered component types
+func componentType(c Component) string {
+	switch c := c.(type) {
+	case Attachment:
+		return c.Type
+	case *Attachment:
+		return c.Type
+	case *Audio:
+		return c.Type
+	case *File:
+		return c.Type
+	case *Card:
+		return c.Type
+	case *Carousel:
+		return c.Type
+	case *List:
+		return c.Type
+	case *Buttons:
+		return c.Type
+	case *QuickReplies:
+		return c.Type
+	case *Delay:
+		return c.Type
+	case *RawComponent:
+		return c.Type
+	}
+	return registeredComponentType(c)
+}
+
+// renderWriter builds the rendering of a component in a format
+type renderWriter struct {
+	format   Format
+	blocks   []string
+	choices  []string
+	numbered int
+}
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"(", `\(`, ")", `\)`, "#", `\#`, "<", `\<`, ">", `\>`,
+)
+
+func (w *renderWriter) escape(s string) string {
+	switch w.format {
+	case FormatMarkdown:
+		return markdownReplacer.Replace(s)
+	case FormatHTML:
+		return html.EscapeString(s)
+	}
+	return s
+}
+
+func (w *renderWriter) bold(s string) string {
+	switch w.format {
+	case FormatMarkdown:
+		return "**" + w.escape(s) + "**"
+	case FormatHTML:
+		return "<strong>" + w.escape(s) + "</strong>"
+	}
+	return s
+}
+
+// safeURL returns whether or not u can be used as a link
+func safeURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto", "tel":
+		return parsed.Opaque != ""
+	}
+	return false
+}
+
+func (w *renderWriter) link(title, u string) string {
+	if !safeURL(u) {
+		return w.escape(title)
+	}
+	switch w.format {
+	case FormatMarkdown:
+		return "[" + w.escape(title) + "](" + strings.Replace(u, ")", "%29", -1) + ")"
+	case FormatHTML:
+		return `<a href="` + html.EscapeString(u) + `">` + w.escape(title) + "</a>"
+	}
+	if title == "" || title == u {
+		return u
+	}
+	return title + ": " + strings.TrimPrefix(u, "tel:")
+}
+
+func (w *renderWriter) image(u string) string {
+	if !safeURL(u) {
+		return ""
+	}
+	switch w.format {
+	case FormatMarkdown:
+		return "![](" + strings.Replace(u, ")", "%29", -1) + ")"
+	case FormatHTML:
+		return `<img src="` + html.EscapeString(u) + `" alt="">`
+	}
+	return u
+}
+
+func (w *renderWriter) paragraph(s string) {
+	if s == "" {
+		return
+	}
+	w.flushChoices()
+	if w.format == FormatHTML {
+		s = "<p>" + s + "</p>"
+	}
+	w.blocks = append(w.blocks, s)
+}
+
+func (w *renderWriter) choice(title string) {
+	w.choices = append(w.choices, title)
+}
+
+// flushChoices writes the pending choices as a numbered list
+func (w *renderWriter) flushChoices() {
+	if len(w.choices) == 0 {
+		return
+	}
+	lines := make([]string, len(w.choices))
+	for i, title := range w.choices {
+		if w.format == FormatHTML {
+			lines[i] = "<li>" + w.escape(title) + "</li>"
+		} else {
+			lines[i] = fmt.Sprintf("%d. %s", w.numbered+i+1, w.escape(title))
+		}
+	}
+	if w.format == FormatHTML {
+		w.blocks = append(w.blocks, fmt.Sprintf(`<ol start="%d">`+"\n%s\n</ol>", w.numbered+1, strings.Join(lines, "\n")))
+	} else {
+		w.blocks = append(w.blocks, strings.Join(lines, "\n"))
+	}
+	w.numbered += len(w.choices)
+	w.choices = nil
+}
+
+func (w *renderWriter) attachment(typ, content string) {
+	switch typ {
+	case "text":
+		text := w.escape(content)
+		if w.format == FormatHTML {
+			text = strings.Replace(text, "\n", "<br>", -1)
+		}
+		w.paragraph(text)
+	case "picture":
+		w.paragraph(w.image(content))
+	default:
+		w.paragraph(w.link(content, content))
+	}
+}
+
+func (w *renderWriter) card(title, subtitle, imageURL string, buttons []CardButton) {
+	if title != "" {
+		w.paragraph(w.bold(title))
+	}
+	w.paragraph(w.escape(subtitle))
+	if imageURL != "" {
+		w.paragraph(w.image(imageURL))
+	}
+	w.buttons(buttons)
+}
+
+func (w *renderWriter) buttons(buttons []CardButton) {
+	for _, b := range buttons {
+		switch b.Kind() {
+		case ButtonPostback:
+			w.choice(b.Title)
+		case ButtonURL, ButtonLogin:
+			w.paragraph(w.link(b.Title, b.Value))
+		case ButtonPhone:
+			w.paragraph(w.link(b.Title, "tel:"+b.Value))
+		}
+	}
+}
+
+func (w *renderWriter) String() string {
+	w.flushChoices()
+	return strings.Join(w.blocks, "\n")
+}
@@ -0,0 +1,103 @@
+package recast
+
+import (
+	"html/template"
+	"testing"
+)
+
+func renderTestMessages() []Component {
+	return []Component{
+		NewTextMessage("Hello <you>"),
+		NewCard("Recast.AI", "Bots_for_all").
+			AddImage("https://recast.ai/logo.png").
+			AddButtons(URLButton("Visit", "https://recast.ai"), PostbackButton("Subscribe", "SUBSCRIBE"), PhoneButton("Call", "+33600000000")),
+		NewQuickReplies("Do you like it?").AddButton("Yes", "yes").AddButton("No", "no"),
+		NewDelay(0),
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{FormatText, "Hello <you>\n\n" +
+			"Recast.AI\nBots_for_all\nhttps://recast.ai/logo.png\nVisit: https://recast.ai\n1. Subscribe\nCall: +33600000000\n\n" +
+			"Do you like it?\n2. Yes\n3. No"},
+		{FormatMarkdown, "Hello \\<you\\>\n\n" +
+			"**Recast.AI**\nBots\\_for\\_all\n![](https://recast.ai/logo.png)\n[Visit](https://recast.ai)\n1. Subscribe\n[Call](tel:+33600000000)\n\n" +
+			"Do you like it?\n2. Yes\n3. No"},
+		{FormatHTML, "<p>Hello &lt;you&gt;</p>\n" +
+			"<p><strong>Recast.AI</strong></p>\n<p>Bots_for_all</p>\n<p><img src=\"https://recast.ai/logo.png\" alt=\"\"></p>\n<p><a href=\"https://recast.ai\">Visit</a></p>\n<ol start=\"1\">\n<li>Subscribe</li>\n</ol>\n<p><a href=\"tel:+33600000000\">Call</a></p>\n" +
+			"<p>Do you like it?</p>\n<ol start=\"2\">\n<li>Yes</li>\n<li>No</li>\n</ol>"},
+	}
+
+	for _, test := range tests {
+		renderer := Renderer{Format: test.format}
+		text, err := renderer.Render(renderTestMessages()...)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if text != test.expected {
+			t.Errorf("Format %d: expected\n%s\nbut instead got\n%s", test.format, test.expected, text)
+		}
+	}
+}
+
+func TestRenderSanitizesLinks(t *testing.T) {
+	renderer := Renderer{Format: FormatHTML}
+	text, err := renderer.Render(NewButtons("<b>Click</b>").AddButton("Run", "web_url", "javascript:alert(1)"))
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if text != "<p>&lt;b&gt;Click&lt;/b&gt;</p>\n<p>Run</p>" {
+		t.Fatalf("Unexpected rendering: %s", text)
+	}
+}
+
+func TestRenderTemplates(t *testing.T) {
+	renderer := Renderer{
+		Format: FormatHTML,
+		Templates: map[string]Template{
+			"card": template.Must(template.New("card").Parse(`<h1>{{.Content.Title}}</h1>`)),
+		},
+	}
+	text, err := renderer.Render(NewCard("<Recast.AI>", ""))
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if text != "<h1>&lt;Recast.AI&gt;</h1>" {
+		t.Fatalf("Unexpected rendering: %s", text)
+	}
+}
+
+func TestComponentType(t *testing.T) {
+	RegisterComponentType("sticker", func() Component { return &testSticker{} })
+
+	components := map[string]Component{
+		"card":         NewCard("title", ""),
+		"picture":      Attachment{Type: "picture"},
+		"quickReplies": NewQuickReplies("Question?"),
+		"hologram":     &RawComponent{Type: "hologram"},
+		"sticker":      &testSticker{},
+		"text":         NewTextMessage("Hello"),
+	}
+	for expected, c := range components {
+		if typ := componentType(c); typ != expected {
+			t.Errorf("Expected %s, but instead got %q", expected, typ)
+		}
+	}
+}
+
+func TestResolveChoice(t *testing.T) {
+	messages := renderTestMessages()
+	tests := map[string]string{"1": "SUBSCRIBE", "2.": "yes", " no ": "no"}
+	for answer, expected := range tests {
+		if value, ok := ResolveChoice(messages, answer); !ok || value != expected {
+			t.Errorf("Expected %s for %q, but instead got %s", expected, answer, value)
+		}
+	}
+	if _, ok := ResolveChoice(messages, "4"); ok {
+		t.Error("There are only 3 choices")
+	}
+}
@@ -0,0 +1,446 @@
+package recast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ErrUnknownReply is returned when rendering a reply which is not defined
+var ErrUnknownReply = errors.New("The reply is not defined")
+
+// ReplyTemplates holds named replies, loaded from YAML or JSON files
+// Each reply has versions by language, and each version is a list of variants,
+// one of them is picked randomly when the reply is rendered:
+//	greetings:
+//	  en:
+//	    - Hello {{.Memory.name.raw}}!
+//	    - messages:
+//	        - Hi!
+//	        - type: quickReplies
+//	          content:
+//	            title: How can I help you?
+//	            buttons:
+//	              - title: Help
+//	                value: help
+//	  fr: Bonjour {{.Memory.name.raw}} !
+// A variant is a text, a single message or a list of messages under the messages key.
+// The strings of the messages are Go templates executed with the data given to Render.
+type ReplyTemplates struct {
+	// DefaultLanguage is used when a reply has no version in the requested language, en by default
+	DefaultLanguage string
+
+	mu      sync.RWMutex
+	replies map[string]map[string][]replyVariant
+	randMu  sync.Mutex
+	rand    *rand.Rand
+}
+
+// replyVariant is a list of messages, where the template strings are replaced by their *template.Template
+type replyVariant []interface{}
+
+// ReplyData is the data available in the reply templates
+//	Hello {{.Memory.name.raw}}, the weather in {{(index .CustomEntities.city 0).Raw}} is sunny
+type ReplyData struct {
+	Memory         map[string]interface{}
+	Entities       Entities
+	CustomEntities map[string][]CustomEntity
+	Language       string
+	// Vars holds additional values set by the bot
+	Vars map[string]interface{}
+}
+
+// NewReplyData returns the template data of a conversation
+func NewReplyData(conv Conversation) ReplyData {
+	return ReplyData{
+		Memory:         conv.Memory,
+		Entities:       conv.Entities,
+		CustomEntities: conv.CustomEntities,
+		Language:       conv.Language,
+		Vars:           map[string]interface{}{},
+	}
+}
+
+// NewDialogReplyData returns the template data of a dialog
+func NewDialogReplyData(dialog Dialog) ReplyData {
+	return ReplyData{
+		Memory:         dialog.DialogConversation.Memory,
+		Entities:       dialog.Nlp.Entities,
+		CustomEntities: dialog.Nlp.CustomEntities,
+		Language:       dialog.DialogConversation.Language,
+		Vars:           map[string]interface{}{},
+	}
+}
+
+// NewReplyTemplates returns an empty set of replies
+func NewReplyTemplates() *ReplyTemplates {
+	return &ReplyTemplates{
+		DefaultLanguage: "en",
+		replies:         map[string]map[string][]replyVariant{},
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// LoadReplyTemplates returns the replies defined in files
+// Files with the .json extension are decoded as JSON, the other ones as YAML
+func LoadReplyTemplates(paths ...string) (*ReplyTemplates, error) {
+	templates := NewReplyTemplates()
+	for _, path := range paths {
+		if err := templates.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// LoadFile adds the replies defined in a YAML or JSON file
+// Replies already defined are replaced
+func (t *ReplyTemplates) LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = t.LoadJSON(data)
+	} else {
+		err = t.LoadYAML(data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// LoadYAML adds the replies defined in a YAML document
+func (t *ReplyTemplates) LoadYAML(data []byte) error {
+	document, err := decodeYAML(data)
+	if err != nil {
+		return err
+	}
+	return t.load(document)
+}
+
+// decodeYAML decodes a YAML document into the values decoded by encoding/json,
+// so that the YAML and JSON documents are loaded the same way
+func decodeYAML(data []byte) (interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return jsonValue(document)
+}
+
+// jsonValue converts the mappings and numbers decoded by yaml to the JSON ones
+func jsonValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("Invalid YAML: key %v is not a string", key)
+			}
+			c, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[k] = c
+		}
+		return converted, nil
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			c, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = c
+		}
+		return converted, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+	return value, nil
+}
+
+// LoadJSON adds the replies defined in a JSON document
+func (t *ReplyTemplates) LoadJSON(data []byte) error {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	return t.load(document)
+}
+
+func (t *ReplyTemplates) load(document interface{}) error {
+	definitions, ok := document.(map[string]interface{})
+	if !ok && document != nil {
+		return fmt.Errorf("Invalid replies: expected a mapping of reply names")
+	}
+
+	replies := map[string]map[string][]replyVariant{}
+	for name, definition := range definitions {
+		// a reply without languages is used for any language
+		languages, ok := definition.(map[string]interface{})
+		if !ok || isReplyVariant(languages) {
+			languages = map[string]interface{}{"": definition}
+		}
+
+		replies[name] = map[string][]replyVariant{}
+		for language, value := range languages {
+			variants, err := compileReplyVariants(name+"."+language, value)
+			if err != nil {
+				return fmt.Errorf("Invalid reply %s (%s): %v", name, language, err)
+			}
+			replies[name][language] = variants
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.replies == nil {
+		t.replies = map[string]map[string][]replyVariant{}
+	}
+	for name, languages := range replies {
+		t.replies[name] = languages
+	}
+	return nil
+}
+
+// Has returns whether or not the reply name is defined
+func (t *ReplyTemplates) Has(name string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, found := t.replies[name]
+	return found
+}
+
+// Render returns the messages of a variant of the reply name in language
+// When the reply has no version in language, the base language (fr for fr-CA) is used,
+// then the default language
+// Using a key missing from the memory or the variables is an error,
+// optional keys can be tested with {{if index .Memory "name"}}
+//	messages, err := templates.Render("greetings", conv.Language, recast.NewReplyData(conv))
+//	err = w.Reply(messages...)
+func (t *ReplyTemplates) Render(name, language string, data interface{}) ([]Component, error) {
+	variant, err := t.variant(name, language)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Component, len(variant))
+	for i, message := range variant {
+		value, err := executeReplyValue(message, data)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot render reply %s: %v", name, err)
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if messages[i], err = decodeComponent(raw); err != nil {
+			return nil, fmt.Errorf("Cannot render reply %s: %v", name, err)
+		}
+	}
+	return messages, nil
+}
+
+// variant picks a random variant of the reply name in language
+func (t *ReplyTemplates) variant(name, language string) (replyVariant, error) {
+	t.mu.RLock()
+	languages, found := t.replies[name]
+	t.mu.RUnlock()
+	if !found {
+		return nil, ErrUnknownReply
+	}
+
+	defaultLanguage := t.DefaultLanguage
+	if defaultLanguage == "" {
+		defaultLanguage = "en"
+	}
+	for _, candidate := range []string{language, baseLanguage(language), defaultLanguage, ""} {
+		variants, found := languages[candidate]
+		if !found || len(variants) == 0 {
+			continue
+		}
+
+		t.randMu.Lock()
+		if t.rand == nil {
+			t.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		i := t.rand.Intn(len(variants))
+		t.randMu.Unlock()
+		return variants[i], nil
+	}
+	return nil, fmt.Errorf("The reply %s has no version in %s", name, language)
+}
+
+// baseLanguage returns the language of a regional language code
+func baseLanguage(language string) string {
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		return language[:i]
+	}
+	return language
+}
+
+// isReplyVariant returns whether or not value is a variant, and not a mapping of languages
+func isReplyVariant(value map[string]interface{}) bool {
+	_, isMessages := value["messages"]
+	_, isMessage := value["type"]
+	return isMessages || isMessage
+}
+
+// compileReplyVariants parses a text, a variant or a list of variants
+func compileReplyVariants(name string, value interface{}) ([]replyVariant, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		list = []interface{}{value}
+	}
+
+	variants := make([]replyVariant, len(list))
+	for i, v := range list {
+		var messages []interface{}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if m, found := v["messages"]; found {
+				if messages, ok = m.([]interface{}); !ok {
+					return nil, fmt.Errorf("messages must be a list")
+				}
+			} else {
+				messages = []interface{}{v}
+			}
+		case nil:
+			return nil, fmt.Errorf("empty variant")
+		default:
+			messages = []interface{}{v}
+		}
+
+		variant := make(replyVariant, len(messages))
+		for j, message := range messages {
+			compiled, err := compileReplyMessage(fmt.Sprintf("%s.%d.%d", name, i, j), message)
+			if err != nil {
+				return nil, err
+			}
+			variant[j] = compiled
+		}
+		variants[i] = variant
+	}
+	return variants, nil
+}
+
+// compileReplyMessage parses the templates of a message, and checks it can be decoded
+func compileReplyMessage(name string, message interface{}) (interface{}, error) {
+	switch m := message.(type) {
+	case string:
+		message = map[string]interface{}{"type": "text", "content": m}
+	case map[string]interface{}:
+		if typ, _ := m["type"].(string); typ == "" {
+			return nil, fmt.Errorf("message type is empty")
+		}
+	default:
+		return nil, fmt.Errorf("a message must be a text or a mapping, got %v", message)
+	}
+
+	compiled, hasTemplates, err := compileReplyValue(name, message)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTemplates {
+		// messages with templates can only be checked once rendered
+		raw, err := json.Marshal(compiled)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := decodeComponent(raw); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+// compileReplyValue replaces the template strings of value by their parsed template
+func compileReplyValue(name string, value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, false, nil
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, false, err
+		}
+		return tmpl, true, nil
+	case map[string]interface{}:
+		compiled := make(map[string]interface{}, len(v))
+		hasTemplates := false
+		for key, item := range v {
+			c, isTemplate, err := compileReplyValue(name, item)
+			if err != nil {
+				return nil, false, err
+			}
+			compiled[key] = c
+			hasTemplates = hasTemplates || isTemplate
+		}
+		return compiled, hasTemplates, nil
+	case []interface{}:
+		compiled := make([]interface{}, len(v))
+		hasTemplates := false
+		for i, item := range v {
+			c, isTemplate, err := compileReplyValue(name, item)
+			if err != nil {
+				return nil, false, err
+			}
+			compiled[i] = c
+			hasTemplates = hasTemplates || isTemplate
+		}
+		return compiled, hasTemplates, nil
+	}
+	return value, false, nil
+}
+
+// executeReplyValue returns a copy of value with the templates executed with data
+func executeReplyValue(value interface{}, data interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case *template.Template:
+		var b bytes.Buffer
+		if err := v.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		return b.String(), nil
+	case map[string]interface{}:
+		executed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			e, err := executeReplyValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			executed[key] = e
+		}
+		return executed, nil
+	case []interface{}:
+		executed := make([]interface{}, len(v))
+		for i, item := range v {
+			e, err := executeReplyValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			executed[i] = e
+		}
+		return executed, nil
+	}
+	return value, nil
+}
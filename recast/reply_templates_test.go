@@ -0,0 +1,107 @@
+package recast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplyTemplates(t *testing.T) {
+	templates, err := LoadReplyTemplates("./test/replies.yaml", "./test/replies.json")
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	conv := Conversation{
+		Language: "fr",
+		Memory:   map[string]interface{}{"name": map[string]interface{}{"raw": "Jean"}},
+		CustomEntities: map[string][]CustomEntity{
+			"city": {{Name: "city", Raw: "Paris", Value: "paris"}},
+		},
+	}
+	data := NewReplyData(conv)
+	data.Vars["user"] = "Jean"
+
+	tests := []struct {
+		name     string
+		language string
+		expected []Component
+	}{
+		{"greetings", "fr", []Component{&Attachment{Type: "text", Content: "Bonjour Jean !"}}},
+		{"greetings", "fr-CA", []Component{&Attachment{Type: "text", Content: "Bonjour Jean !"}}},
+		{"greetings", "de", []Component{&Attachment{Type: "text", Content: "Hello Jean!"}}},
+		{"goodbye", "fr", []Component{&Attachment{Type: "text", Content: "Bye!"}}},
+		{"help", "en", []Component{
+			NewQuickReplies("How can I help you, Jean?").AddButton("Weather", "weather").AddButton("Talk to a human", "human"),
+			NewDelay(1500 * 1e6),
+		}},
+		{"weather", "en", []Component{
+			NewCard("Weather in Paris", "Sunny").AddButtons(URLButton("Forecast", "https://example.com/paris")),
+		}},
+	}
+	for _, test := range tests {
+		messages, err := templates.Render(test.name, test.language, data)
+		if err != nil {
+			t.Fatalf("%s: expected err to be nil, but instead got %+v", test.name, err)
+		}
+		if !reflect.DeepEqual(messages, test.expected) {
+			t.Fatalf("%s (%s): expected %#v, but instead got %#v", test.name, test.language, test.expected, messages)
+		}
+	}
+
+	// values printed as <no value> by the templates are kept
+	data.Vars["user"] = "<no value>"
+	messages, err := templates.Render("help", "en", data)
+	if err != nil || messages[0].(*QuickReplies).Content.Title != "How can I help you, <no value>?" {
+		t.Fatalf("Unexpected messages %+v, err %v", messages, err)
+	}
+	if _, err := templates.Render("greetings", "en", NewReplyData(Conversation{})); err == nil || !strings.Contains(err.Error(), "map has no entry for key") {
+		t.Fatalf("Expected a missing key error, but instead got %v", err)
+	}
+
+	if _, err := templates.Render("unknown", "en", data); err != ErrUnknownReply {
+		t.Fatalf("Expected ErrUnknownReply, but instead got %v", err)
+	}
+}
+
+func TestReplyTemplatesVariants(t *testing.T) {
+	templates := NewReplyTemplates()
+	err := templates.LoadYAML([]byte("hello:\n  en: [Hi, Hello, Hey]\n"))
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		messages, err := templates.Render("hello", "en", nil)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		seen[messages[0].(*Attachment).Content] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("Expected the 3 variants to be used, got %v", seen)
+	}
+}
+
+func TestReplyTemplatesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"template": `{"hello": {"en": "Hello {{.Memory"}}`,
+		"type":     `{"hello": {"en": {"messages": [{"content": "Hello"}]}}}`,
+		"content":  `{"hello": {"en": {"type": "delay", "content": "soon"}}}`,
+	}
+	// YAML reads an unquoted template action as a flow mapping
+	if err := NewReplyTemplates().LoadYAML([]byte("hello: {{.Memory.name}}\n")); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if err := NewReplyTemplates().LoadYAML([]byte("hello:\n  en: \"{{.Memory.name}}\"\n")); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+
+	for name, data := range tests {
+		err := NewReplyTemplates().LoadJSON([]byte(data))
+		if err == nil || !strings.HasPrefix(err.Error(), "Invalid reply hello (en)") {
+			t.Fatalf("%s: expected an invalid reply error, got %v", name, err)
+		}
+	}
+}
@@ -1,13 +1,12 @@
 package recast
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/parnurzeal/gorequest"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"path/filepath"
 )
 
@@ -20,6 +19,19 @@ var (
 type RequestClient struct {
 	Token    string
 	Language string
+	// Endpoint overrides the location of the Recast.AI API (https://api.recast.ai/)
+	// It is mostly useful to run the client against a test server
+	Endpoint string
+	// HTTPClient sends the requests when set
+	HTTPClient *http.Client
+	// Transport is used to perform the requests when set and HTTPClient is not
+	// Otherwise requests go through the proxy set in RECAST_PROXY if any
+	Transport http.RoundTripper
+	// UserSlug, BotSlug and BotVersion identify the bot on the build API
+	// They are only needed to manage the dialog conversations, BotVersion defaults to "v1"
+	UserSlug   string
+	BotSlug    string
+	BotVersion string
 }
 
 // ReqOpts are used to overwrite the client token and language on a per request baises if a user wises to do so
@@ -40,6 +52,10 @@ type rawEntities struct {
 	} `json:"results"`
 }
 
+func (c *RequestClient) httpClient() *http.Client {
+	return newHTTPClient(c.HTTPClient, c.Transport)
+}
+
 // AnalyzeText processes a text request to Recast.AI API and returns a Response
 // opts can be used to specify a token and/or language to use for this request
 // Set opts to nil if you want the request to use the client's token and language
@@ -53,7 +69,6 @@ type rawEntities struct {
 func (c *RequestClient) AnalyzeText(text string, opts *ReqOpts) (Response, error) {
 	lang := c.Language
 	token := c.Token
-	httpClient := gorequest.New()
 	if opts != nil {
 		if opts.Language != "" {
 			lang = opts.Language
@@ -81,24 +96,26 @@ func (c *RequestClient) AnalyzeText(text string, opts *ReqOpts) (Response, error
 
 	var response respJSON
 
-	resp, body, requestErr := httpClient.
-		Post(requestEndpoint).
-		Send(send).
-		Set("Authorization", fmt.Sprintf("Token %s", token)).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return Response{}, requestErr[0]
+	req, err := newRequest(context.Background(), http.MethodPost, endpointURL(c.Endpoint, requestEndpoint), token, send)
+	if err != nil {
+		return Response{}, err
+	}
+	resp, body, err := end(c.httpClient(), req)
+	if err != nil {
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 
+	if err = json.Unmarshal(body, &response); err != nil {
+		return Response{}, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return Response{}, fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
 	}
 
 	var entities rawEntities
-	err := json.Unmarshal(body, &entities)
+	err = json.Unmarshal(body, &entities)
 	if err != nil {
 		return Response{}, err
 	}
@@ -120,7 +137,6 @@ func (c *RequestClient) AnalyzeText(text string, opts *ReqOpts) (Response, error
 func (c *RequestClient) AnalyzeFile(filename string, opts *ReqOpts) (Response, error) {
 	lang := c.Language
 	token := c.Token
-	httpClient := gorequest.New()
 
 	if opts != nil {
 		if opts.Language != "" {
@@ -156,18 +172,21 @@ func (c *RequestClient) AnalyzeFile(filename string, opts *ReqOpts) (Response, e
 		Message string   `json:"message"`
 	}
 
-	resp, body, requestErr := httpClient.Post(requestEndpoint).
-		Type("multipart").
-		SendFile(fileContent, "filename", "voice").
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", token)).EndStruct(&response)
-
-	if requestErr != nil {
-		return Response{}, requestErr[0]
+	fields := map[string]string{"text": send.Text, "language": send.Language}
+	req, err := newFileRequest(context.Background(), http.MethodPost, endpointURL(c.Endpoint, requestEndpoint), token, "voice", "filename", fileContent, fields)
+	if err != nil {
+		return Response{}, err
+	}
+	resp, body, err := end(c.httpClient(), req)
+	if err != nil {
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 
+	if err = json.Unmarshal(body, &response); err != nil {
+		return Response{}, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return Response{}, fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
 	}
@@ -211,12 +230,15 @@ type requestForms struct {
 //	// This request will be processed in english
 //	conversation, err := client.ConverseText("Hello what is the weahter in London?", &opts)
 func (c *RequestClient) ConverseText(text string, opts *ConverseOpts) (Conversation, error) {
+	return c.converseText(context.Background(), text, opts)
+}
+
+func (c *RequestClient) converseText(ctx context.Context, text string, opts *ConverseOpts) (Conversation, error) {
 	var memory map[string]map[string]interface{}
 	var conversationToken string
 	lang := c.Language
 	token := c.Token
 
-	httpClient := gorequest.New()
 	if opts != nil {
 		if opts.Language != "" {
 			lang = opts.Language
@@ -248,18 +270,20 @@ func (c *RequestClient) ConverseText(text string, opts *ConverseOpts) (Conversat
 		Message string       `json:"message"`
 	}
 
-	resp, body, requestErr := httpClient.
-		Post(converseEndpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", token)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return Conversation{}, requestErr[0]
+	req, err := newRequest(ctx, http.MethodPost, endpointURL(c.Endpoint, converseEndpoint), token, send)
+	if err != nil {
+		return Conversation{}, err
+	}
+	resp, body, err := end(c.httpClient(), req)
+	if err != nil {
+		return Conversation{}, err
 	}
 	defer resp.Body.Close()
 
+	if err = json.Unmarshal(body, &response); err != nil {
+		return Conversation{}, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return Conversation{}, fmt.Errorf("Request failed (%s): %s", resp.Status, response.Message)
 	}
@@ -267,12 +291,20 @@ func (c *RequestClient) ConverseText(text string, opts *ConverseOpts) (Conversat
 	conversation := response.Results
 
 	var entities rawEntities
-	err := json.Unmarshal(body, &entities)
+	err = json.Unmarshal(body, &entities)
 	if err != nil {
 		return Conversation{}, err
 	}
 	conversation.CustomEntities = getCustomEntities(entities.Results.Entities)
 	conversation.AuthorizationToken = token
+	conversation.client = c
+	conversation.History = []ConversationTurn{{
+		Text:      text,
+		Replies:   conversation.Replies,
+		Action:    conversation.Action,
+		Sentiment: conversation.Sentiment,
+		Timestamp: conversation.Timestamp,
+	}}
 
 	return conversation, nil
 }
@@ -282,26 +314,65 @@ type DialogOpts struct {
 	Language       string
 	ConversationID string
 	Token          string
+	// Memory is the initial memory of the conversation
+	Memory map[string]interface{}
+	// LogLevel sets the log level of the bot for this request ("info" or "debug")
+	LogLevel string
+	// Message is sent in place of the text when set, to answer
+	// a postback, a quick reply or to send an attachment
+	//	dialog, err := client.DialogText("", &recast.DialogOpts{Message: recast.NewDialogPostback("START")})
+	Message *DialogMessage
 }
 
-type dialogForm struct {
-	Language       string            `json:"language"`
-	ConversationID string            `json:"conversation_id"`
-	Message        dialogFormMessage `json:"message"`
+// DialogMessage is a message of any type sent to the dialog endpoint
+type DialogMessage struct {
+	Type    string      `json:"type"`
+	Content interface{} `json:"content"`
+}
+
+// NewDialogMessage returns a message of type typ with content
+func NewDialogMessage(typ string, content interface{}) *DialogMessage {
+	return &DialogMessage{Type: typ, Content: content}
+}
+
+// NewDialogText returns a text message
+func NewDialogText(text string) *DialogMessage {
+	return NewDialogMessage("text", text)
+}
+
+// NewDialogPostback returns the message sent when the user clicks on a postback button
+func NewDialogPostback(value string) *DialogMessage {
+	return NewDialogMessage("postback", value)
+}
+
+// NewDialogQuickReply returns the message sent when the user picks a quick reply
+func NewDialogQuickReply(title, value string) *DialogMessage {
+	return NewDialogMessage("quickReply", map[string]string{"title": title, "value": value})
 }
 
-type dialogFormMessage struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
+// NewDialogAttachment returns an attachment message, typ can be "picture", "video", "audio" or "file"
+func NewDialogAttachment(typ, url string) *DialogMessage {
+	return NewDialogMessage(typ, url)
+}
+
+type dialogForm struct {
+	Language       string                 `json:"language"`
+	ConversationID string                 `json:"conversation_id"`
+	Message        DialogMessage          `json:"message"`
+	Memory         map[string]interface{} `json:"memory,omitempty"`
+	LogLevel       string                 `json:"log_level,omitempty"`
 }
 
 //DialogText retrieve all metadata, intents and replies from a sentence
 func (c *RequestClient) DialogText(text string, opts *DialogOpts) (Dialog, error) {
-	var conversationID string
+	return c.dialogText(context.Background(), text, opts)
+}
+
+func (c *RequestClient) dialogText(ctx context.Context, text string, opts *DialogOpts) (Dialog, error) {
 	lang := c.Language
 	token := c.Token
+	send := dialogForm{Message: DialogMessage{Type: "text", Content: text}}
 
-	httpClient := gorequest.New()
 	if opts != nil {
 		if opts.Language != "" {
 			lang = opts.Language
@@ -309,20 +380,18 @@ func (c *RequestClient) DialogText(text string, opts *DialogOpts) (Dialog, error
 		if opts.Token != "" {
 			token = opts.Token
 		}
-		if opts.ConversationID != "" {
-			conversationID = opts.ConversationID
+		if opts.Message != nil {
+			send.Message = *opts.Message
 		}
+		send.ConversationID = opts.ConversationID
+		send.Memory = opts.Memory
+		send.LogLevel = opts.LogLevel
 	}
 
 	if token == "" {
 		return Dialog{}, ErrTokenNotSet
 	}
-
-	send := dialogForm{
-		Message:        dialogFormMessage{Type: "text", Content: text},
-		ConversationID: conversationID,
-		Language:       lang,
-	}
+	send.Language = lang
 
 	type respJSON struct {
 		Results json.RawMessage `json:"results"`
@@ -330,18 +399,20 @@ func (c *RequestClient) DialogText(text string, opts *DialogOpts) (Dialog, error
 	}
 	var response respJSON
 
-	resp, body, requestErr := httpClient.
-		Post(dialogEndpoint).
-		Send(send).
-		Proxy(os.Getenv("RECAST_PROXY")).
-		Set("Authorization", fmt.Sprintf("Token %s", token)).
-		EndStruct(&response)
-
-	if requestErr != nil {
-		return Dialog{}, requestErr[0]
+	req, err := newRequest(ctx, http.MethodPost, endpointURL(c.Endpoint, dialogEndpoint), token, send)
+	if err != nil {
+		return Dialog{}, err
+	}
+	resp, body, err := end(c.httpClient(), req)
+	if err != nil {
+		return Dialog{}, err
 	}
 	defer resp.Body.Close()
 
+	if err = json.Unmarshal(body, &response); err != nil {
+		return Dialog{}, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return Dialog{}, fmt.Errorf("Request failed (%s): %s", resp.Status, body)
 	}
@@ -350,5 +421,8 @@ func (c *RequestClient) DialogText(text string, opts *DialogOpts) (Dialog, error
 	if err != nil {
 		return Dialog{}, fmt.Errorf("Json parsing failed: %+v", err)
 	}
+	dialog.client = c
+	dialog.token = token
+	dialog.History = []DialogTurn{{Text: text, Message: send.Message, Messages: dialog.Messages}}
 	return dialog, nil
 }
@@ -2,7 +2,6 @@ package recast
 
 import (
 	"errors"
-	"regexp"
 	"time"
 )
 
@@ -54,15 +53,8 @@ type Response struct {
 	CustomEntities     map[string][]CustomEntity
 }
 
-func (r Response) isType(exp string) bool {
-	regex, err := regexp.Compile("^" + exp + "\\w*")
-	if err != nil {
-		return false
-	}
-	if regex.Find([]byte(r.Type)) != nil {
-		return true
-	}
-	return false
+func (r Response) isType(category QuestionCategory) bool {
+	return r.QuestionType().Coarse() == category
 }
 
 // Intent returns the first matched intent, or an error if no intent where matched
@@ -75,32 +67,32 @@ func (r Response) Intent() (Intent, error) {
 
 // IsAbbreviation returns whether or not the sentence is asking for an abbreviation
 func (r Response) IsAbbreviation() bool {
-	return r.isType(TypeAbbreviation)
+	return r.isType(QuestionAbbreviation)
 }
 
 // IsEntity returns whether or not the sentence is asking for an entity
 func (r Response) IsEntity() bool {
-	return r.isType(TypeEntity)
+	return r.isType(QuestionEntity)
 }
 
 // IsDescription returns whether or not the sentence is asking for an description
 func (r Response) IsDescription() bool {
-	return r.isType(TypeDescription)
+	return r.isType(QuestionDescription)
 }
 
 // IsHuman returns whether or not the sentence is asking for an human
 func (r Response) IsHuman() bool {
-	return r.isType(TypeHuman)
+	return r.isType(QuestionHuman)
 }
 
 // IsLocation returns whether or not the sentence is asking for an location
 func (r Response) IsLocation() bool {
-	return r.isType(TypeLocation)
+	return r.isType(QuestionLocation)
 }
 
 // IsNumber returns whether or not the sentence is asking for an number
 func (r Response) IsNumber() bool {
-	return r.isType(TypeNumber)
+	return r.isType(QuestionNumber)
 }
 
 // IsPositive returns whether or not the sentiment is positive
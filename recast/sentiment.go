@@ -0,0 +1,323 @@
+package recast
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Sentiment is the sentiment of a sentence, Response.Sentiment and Conversation.Sentiment
+// hold one of the Sentiment constants
+type Sentiment string
+
+// ParseSentiment returns the sentiment named s
+// It accepts the values of the API and spelled out names such as "very positive"
+func ParseSentiment(s string) (Sentiment, error) {
+	name := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+	switch name {
+	case SentimentVeryPositive, "verypositive":
+		return SentimentVeryPositive, nil
+	case SentimentPositive:
+		return SentimentPositive, nil
+	case SentimentNeutral:
+		return SentimentNeutral, nil
+	case SentimentNegative:
+		return SentimentNegative, nil
+	case SentimentVeryNegative, "verynegative":
+		return SentimentVeryNegative, nil
+	}
+	return "", fmt.Errorf("Unknown sentiment %q", s)
+}
+
+// SentimentFromScore returns the sentiment of a score, rounded and bounded to -2..+2
+func SentimentFromScore(score float64) Sentiment {
+	switch {
+	case score >= 1.5:
+		return SentimentVeryPositive
+	case score >= 0.5:
+		return SentimentPositive
+	case score > -0.5:
+		return SentimentNeutral
+	case score > -1.5:
+		return SentimentNegative
+	}
+	return SentimentVeryNegative
+}
+
+// String returns the API value of the sentiment
+func (s Sentiment) String() string {
+	return string(s)
+}
+
+// Score returns the sentiment as a number, from -2 (very negative) to +2 (very positive)
+// Unknown sentiments are neutral
+func (s Sentiment) Score() int {
+	switch s {
+	case SentimentVeryPositive:
+		return 2
+	case SentimentPositive:
+		return 1
+	case SentimentNegative:
+		return -1
+	case SentimentVeryNegative:
+		return -2
+	}
+	return 0
+}
+
+// IsNegative returns whether or not the sentiment is negative or very negative
+func (s Sentiment) IsNegative() bool {
+	return s.Score() < 0
+}
+
+// Act is the kind of sentence, Response.Act holds one of the Act constants
+type Act string
+
+// ParseAct returns the act named s
+func ParseAct(s string) (Act, error) {
+	switch act := Act(strings.ToLower(strings.TrimSpace(s))); act {
+	case ActAssert, ActCommand, ActWhQuery, ActYnQuery:
+		return act, nil
+	}
+	return "", fmt.Errorf("Unknown act %q", s)
+}
+
+// String returns the API value of the act
+func (a Act) String() string {
+	return string(a)
+}
+
+// IsQuestion returns whether or not the act is a wh or yes-no question
+func (a Act) IsQuestion() bool {
+	return a == ActWhQuery || a == ActYnQuery
+}
+
+// QuestionCategory is the coarse category of a question type
+type QuestionCategory string
+
+// Question categories, they are the prefix of the question types
+const (
+	QuestionAbbreviation QuestionCategory = "abbr"
+	QuestionEntity       QuestionCategory = "enty"
+	QuestionDescription  QuestionCategory = "desc"
+	QuestionHuman        QuestionCategory = "hum"
+	QuestionLocation     QuestionCategory = "loc"
+	QuestionNumber       QuestionCategory = "num"
+)
+
+var questionCategories = map[QuestionCategory]bool{
+	QuestionAbbreviation: true,
+	QuestionEntity:       true,
+	QuestionDescription:  true,
+	QuestionHuman:        true,
+	QuestionLocation:     true,
+	QuestionNumber:       true,
+}
+
+// QuestionType is the type of the answer expected by a question, held in Response.Type
+// It is made of a coarse category and a fine category, such as loc:city
+type QuestionType string
+
+// ParseQuestionType returns the question type s
+func ParseQuestionType(s string) (QuestionType, error) {
+	t := QuestionType(strings.ToLower(strings.TrimSpace(s)))
+	if !strings.Contains(string(t), ":") || !questionCategories[t.Coarse()] {
+		return "", fmt.Errorf("Unknown question type %q", s)
+	}
+	return t, nil
+}
+
+// String returns the API value of the question type
+func (t QuestionType) String() string {
+	return string(t)
+}
+
+// Coarse returns the category of the question type, such as loc for loc:city
+func (t QuestionType) Coarse() QuestionCategory {
+	coarse := string(t)
+	if i := strings.Index(coarse, ":"); i >= 0 {
+		coarse = coarse[:i]
+	}
+	return QuestionCategory(coarse)
+}
+
+// Fine returns the precise type of the question type, such as city for loc:city
+func (t QuestionType) Fine() string {
+	if i := strings.Index(string(t), ":"); i >= 0 {
+		return string(t)[i+1:]
+	}
+	return ""
+}
+
+// SentimentKind returns the sentiment of the sentence, empty if it is unknown
+func (r Response) SentimentKind() Sentiment {
+	s, _ := ParseSentiment(r.Sentiment)
+	return s
+}
+
+// ActKind returns the act of the sentence, empty if it is unknown
+func (r Response) ActKind() Act {
+	a, _ := ParseAct(r.Act)
+	return a
+}
+
+// QuestionType returns the type of the question, empty if the sentence is not a question
+func (r Response) QuestionType() QuestionType {
+	t, _ := ParseQuestionType(r.Type)
+	return t
+}
+
+// SentimentKind returns the sentiment of the last sentence, empty if it is unknown
+func (conv Conversation) SentimentKind() Sentiment {
+	s, _ := ParseSentiment(conv.Sentiment)
+	return s
+}
+
+// SentimentTracker aggregates the sentiments of the turns of a conversation,
+// to detect when a user gets upset and the conversation should be escalated
+//	tracker := recast.NewSentimentTracker()
+//	tracker.AddResponse(response)
+//	if tracker.ShouldEscalate() {
+//		// hand the conversation over to a human
+//	}
+type SentimentTracker struct {
+	// Window is the number of recent turns used by Average and Trend
+	Window int
+	// Threshold is the average score below which the conversation is escalated
+	Threshold float64
+	// MaxNegative is the number of consecutive negative turns after which the conversation is escalated
+	MaxNegative int
+	// TrendThreshold is the drop of the score by turn over a full window above which
+	// the conversation is escalated, 0 disables the trend
+	TrendThreshold float64
+
+	mu     sync.Mutex
+	scores []int
+}
+
+// NewSentimentTracker returns a tracker escalating when the average of the last 5 turns
+// is worse than negative, when the score drops by half a point by turn over the last 5 turns,
+// or after 3 negative turns in a row
+func NewSentimentTracker() *SentimentTracker {
+	return &SentimentTracker{Window: 5, Threshold: -1, MaxNegative: 3, TrendThreshold: 0.5}
+}
+
+// Add records the sentiment of a turn
+func (t *SentimentTracker) Add(s Sentiment) *SentimentTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scores = append(t.scores, s.Score())
+	return t
+}
+
+// AddResponse records the sentiment of an analyzed sentence
+func (t *SentimentTracker) AddResponse(r Response) *SentimentTracker {
+	return t.Add(r.SentimentKind())
+}
+
+// AddConversation records the sentiment of each turn of the conversation history
+func (t *SentimentTracker) AddConversation(conv Conversation) *SentimentTracker {
+	for _, turn := range conv.History {
+		s, _ := ParseSentiment(turn.Sentiment)
+		t.Add(s)
+	}
+	return t
+}
+
+// Len returns the number of recorded turns
+func (t *SentimentTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.scores)
+}
+
+// window returns the scores of the recent turns
+func (t *SentimentTracker) window() []int {
+	if t.Window > 0 && len(t.scores) > t.Window {
+		return t.scores[len(t.scores)-t.Window:]
+	}
+	return t.scores
+}
+
+// Average returns the average score of the recent turns, 0 if there is none
+func (t *SentimentTracker) Average() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scores := t.window()
+	if len(scores) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, score := range scores {
+		sum += score
+	}
+	return float64(sum) / float64(len(scores))
+}
+
+// Trend returns the variation of the score by turn over the recent turns,
+// the slope of their linear regression: it is negative when the user gets upset
+func (t *SentimentTracker) Trend() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return trend(t.window())
+}
+
+// trend returns the slope of the linear regression of scores
+func trend(scores []int) float64 {
+	n := float64(len(scores))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, score := range scores {
+		x, y := float64(i), float64(score)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
+
+// Current returns the sentiment of the average score of the recent turns
+func (t *SentimentTracker) Current() Sentiment {
+	return SentimentFromScore(t.Average())
+}
+
+// ShouldEscalate returns whether or not the conversation should be handed over:
+// the last turn is very negative, the average of the recent turns is below Threshold,
+// the trend over a full window is below -TrendThreshold, or the last MaxNegative turns are negative
+func (t *SentimentTracker) ShouldEscalate() bool {
+	if t.Len() == 0 {
+		return false
+	}
+	if t.Average() < t.Threshold {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.scores[len(t.scores)-1] == Sentiment(SentimentVeryNegative).Score() {
+		return true
+	}
+	if scores := t.window(); t.TrendThreshold > 0 && t.Window > 1 && len(scores) == t.Window && trend(scores) <= -t.TrendThreshold {
+		return true
+	}
+	if t.MaxNegative <= 0 || len(t.scores) < t.MaxNegative {
+		return false
+	}
+	for _, score := range t.scores[len(t.scores)-t.MaxNegative:] {
+		if score >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset forgets the recorded turns, once the conversation was escalated
+func (t *SentimentTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scores = nil
+}
@@ -0,0 +1,116 @@
+package recast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSentiment(t *testing.T) {
+	tests := map[string]int{
+		"vpositive":     2,
+		"Very positive": 2,
+		"positive":      1,
+		"neutral":       0,
+		"negative":      -1,
+		"very_negative": -2,
+		"vnegative":     -2,
+	}
+	for s, score := range tests {
+		sentiment, err := ParseSentiment(s)
+		if err != nil {
+			t.Fatalf("Expected err to be nil, but instead got %+v", err)
+		}
+		if sentiment.Score() != score {
+			t.Fatalf("%s: expected score %d, but instead got %d", s, score, sentiment.Score())
+		}
+		if SentimentFromScore(float64(score)) != sentiment {
+			t.Fatalf("%s: expected %s from its score", s, sentiment)
+		}
+	}
+	if _, err := ParseSentiment("happy"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+	if r := (Response{Sentiment: "vnegative"}); r.SentimentKind() != SentimentVeryNegative || !r.SentimentKind().IsNegative() {
+		t.Fatalf("Unexpected sentiment %s", r.SentimentKind())
+	}
+}
+
+func TestQuestionType(t *testing.T) {
+	r := Response{Act: "wh-query", Type: "loc:city"}
+	if r.ActKind() != ActWhQuery || !r.ActKind().IsQuestion() {
+		t.Fatalf("Unexpected act %s", r.ActKind())
+	}
+	if r.QuestionType().Coarse() != QuestionLocation || r.QuestionType().Fine() != "city" {
+		t.Fatalf("Unexpected question type %s", r.QuestionType())
+	}
+	if !r.IsLocation() || r.IsHuman() {
+		t.Fatal("The response should only be a location question")
+	}
+
+	for _, s := range []string{"", "city", "unknown:city"} {
+		if _, err := ParseQuestionType(s); err == nil {
+			t.Fatalf("%q: expected err not to be nil, but instead got nil", s)
+		}
+	}
+	if _, err := ParseAct("question"); err == nil {
+		t.Fatal("Expected err not to be nil, but instead got nil")
+	}
+}
+
+func TestSentimentTracker(t *testing.T) {
+	tracker := NewSentimentTracker()
+	if tracker.ShouldEscalate() {
+		t.Fatal("An empty conversation should not be escalated")
+	}
+
+	tracker.Add(SentimentNegative)
+	if tracker.ShouldEscalate() {
+		t.Fatal("A single negative first turn should not escalate the conversation")
+	}
+
+	tracker.Reset()
+	tracker.Add(SentimentPositive).Add(SentimentNeutral).Add(SentimentNegative)
+	if tracker.ShouldEscalate() {
+		t.Fatal("A single negative turn should not escalate the conversation")
+	}
+	if trend := tracker.Trend(); math.Abs(trend+1) > 1e-9 {
+		t.Fatalf("Expected a trend of -1, but instead got %f", trend)
+	}
+
+	tracker.Add(SentimentNegative).Add(SentimentNegative)
+	if !tracker.ShouldEscalate() {
+		t.Fatal("Three negative turns in a row should escalate the conversation")
+	}
+	// the average of the last 5 turns is -0.4
+	if tracker.Current() != SentimentNeutral {
+		t.Fatalf("Expected the current sentiment to be neutral, but instead got %s", tracker.Current())
+	}
+
+	tracker.Reset()
+	tracker.Add(SentimentPositive).Add(SentimentVeryNegative)
+	if !tracker.ShouldEscalate() {
+		t.Fatal("A very negative turn should escalate the conversation")
+	}
+
+	// the score drops by turn over the full window, with no negative streak
+	tracker.Reset()
+	tracker.Add(SentimentVeryPositive).Add(SentimentPositive).Add(SentimentNeutral).Add(SentimentNeutral)
+	if tracker.ShouldEscalate() {
+		t.Fatal("The trend should only be used over a full window")
+	}
+	tracker.Add(SentimentNegative)
+	if !tracker.ShouldEscalate() {
+		t.Fatalf("A downward trend of %f should escalate the conversation", tracker.Trend())
+	}
+	tracker.Reset()
+	tracker.Add(SentimentPositive).Add(SentimentPositive).Add(SentimentPositive).Add(SentimentPositive).Add(SentimentNeutral)
+	if tracker.ShouldEscalate() {
+		t.Fatalf("A slight trend of %f should not escalate the conversation", tracker.Trend())
+	}
+
+	conv := Conversation{History: []ConversationTurn{{Sentiment: "vnegative"}, {Sentiment: "negative"}}}
+	tracker = NewSentimentTracker().AddConversation(conv)
+	if tracker.Len() != 2 || tracker.Average() != -1.5 || !tracker.ShouldEscalate() {
+		t.Fatalf("Unexpected tracker of the conversation: %d turns, average %f", tracker.Len(), tracker.Average())
+	}
+}
@@ -0,0 +1,369 @@
+package recast
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNoSessionKey is returned when a session is used without key
+var ErrNoSessionKey = errors.New("The session key is empty")
+
+// Session holds the state of a conversation between two calls to the API
+type Session struct {
+	ConversationToken    string                 `json:"conversation_token,omitempty"`
+	DialogConversationID string                 `json:"dialog_conversation_id,omitempty"`
+	Language             string                 `json:"language,omitempty"`
+	Memory               map[string]interface{} `json:"memory,omitempty"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+}
+
+// SessionStore is implemented by the session storage backends
+// Sessions are usually keyed by connector conversation ID
+type SessionStore interface {
+	// Load returns the session stored for key
+	// found is false when there is no session for key
+	Load(key string) (session Session, found bool, err error)
+	// Save stores session for key
+	Save(key string, session Session) error
+	// Delete removes the session stored for key
+	Delete(key string) error
+}
+
+// MemorySessionStore keeps the sessions in memory
+// It is safe for concurrent use
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewMemorySessionStore returns an empty MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[string]Session{}}
+}
+
+// Load implements SessionStore
+func (s *MemorySessionStore) Load(key string) (Session, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, found := s.sessions[key]
+	return session, found, nil
+}
+
+// Save implements SessionStore
+func (s *MemorySessionStore) Save(key string, session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[key] = session
+	return nil
+}
+
+// Delete implements SessionStore
+func (s *MemorySessionStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+	return nil
+}
+
+// FileSessionStore keeps the sessions in a single JSON file
+// The file is rewritten atomically on every change, so the sessions survive
+// a restart of the bot. It is safe for concurrent use within one process.
+type FileSessionStore struct {
+	path     string
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewFileSessionStore opens the sessions stored in the file at path
+// The file is created on the first save if it does not exist
+func NewFileSessionStore(path string) (*FileSessionStore, error) {
+	s := &FileSessionStore{path: path, sessions: map[string]Session{}}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.sessions); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Load implements SessionStore
+func (s *FileSessionStore) Load(key string) (Session, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, found := s.sessions[key]
+	return session, found, nil
+}
+
+// Save implements SessionStore
+func (s *FileSessionStore) Save(key string, session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[key] = session
+	return s.write()
+}
+
+// Delete implements SessionStore
+func (s *FileSessionStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+	return s.write()
+}
+
+func (s *FileSessionStore) write() error {
+	data, err := json.Marshal(s.sessions)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// RedisClient is the subset of a Redis client used by RedisSessionStore
+// Any key-value store with expiration can implement it
+type RedisClient interface {
+	// Get returns the value of key, found is false if the key does not exist
+	Get(key string) (value []byte, found bool, err error)
+	// Set stores value for key, the key never expires if ttl is zero
+	Set(key string, value []byte, ttl time.Duration) error
+	// Del removes key
+	Del(key string) error
+}
+
+// RedisSessionStore keeps the sessions in a Redis compatible store
+//	store := &recast.RedisSessionStore{Client: myRedisAdapter, Prefix: "mybot:", TTL: 24 * time.Hour}
+type RedisSessionStore struct {
+	Client RedisClient
+	// Prefix is prepended to all the session keys
+	Prefix string
+	// TTL is the expiration of the sessions, they never expire if it is zero
+	TTL time.Duration
+}
+
+// Load implements SessionStore
+func (s *RedisSessionStore) Load(key string) (Session, bool, error) {
+	data, found, err := s.Client.Get(s.Prefix + key)
+	if err != nil || !found {
+		return Session{}, false, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return Session{}, false, err
+	}
+	return session, true, nil
+}
+
+// Save implements SessionStore
+func (s *RedisSessionStore) Save(key string, session Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(s.Prefix+key, data, s.TTL)
+}
+
+// Delete implements SessionStore
+func (s *RedisSessionStore) Delete(key string) error {
+	return s.Client.Del(s.Prefix + key)
+}
+
+// SessionClient wraps a RequestClient to thread the conversation state
+// through a SessionStore, so that consecutive calls with the same key
+// continue the same conversation
+//	sessions := recast.SessionClient{
+//		Client: &recast.RequestClient{Token: "YOUR_TOKEN"},
+//		Store:  recast.NewMemorySessionStore(),
+//	}
+//	client.UseHandler(recast.MessageHandlerFunc(func(w recast.MessageWriter, m recast.Message) {
+//		dialog, err := sessions.DialogText(m.ConversationID, m.Attachment.Content, nil)
+//		...
+//	}))
+// The turns of a key are serialized, so that concurrent messages of a conversation
+// do not overwrite each other's session. The lock only covers the current process,
+// bots sharing a store between several instances have to route a conversation to a single instance.
+type SessionClient struct {
+	Client *RequestClient
+	Store  SessionStore
+
+	locks keyLocks
+}
+
+// keyLocks holds a mutex per key, removed once it is not used anymore
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function unlocking it
+func (l *keyLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*keyLock{}
+	}
+	kl, found := l.locks[key]
+	if !found {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+		l.mu.Lock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (s *SessionClient) load(key string) (Session, error) {
+	if key == "" {
+		return Session{}, ErrNoSessionKey
+	}
+	session, _, err := s.Store.Load(key)
+	return session, err
+}
+
+// ConverseText calls RequestClient.ConverseText within the conversation stored for key
+// The conversation token, memory and language are taken from the session unless set in opts
+func (s *SessionClient) ConverseText(key, text string, opts *ConverseOpts) (Conversation, error) {
+	if key == "" {
+		return Conversation{}, ErrNoSessionKey
+	}
+	defer s.locks.lock(key)()
+	session, err := s.load(key)
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	var o ConverseOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.ConversationToken == "" {
+		o.ConversationToken = session.ConversationToken
+	}
+	if o.Language == "" {
+		o.Language = session.Language
+	}
+	if o.Memory == nil && len(session.Memory) > 0 {
+		o.Memory = converseMemory(session.Memory)
+	}
+
+	conversation, err := s.Client.ConverseText(text, &o)
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	session.ConversationToken = conversation.ConversationToken
+	if conversation.Language != "" {
+		session.Language = conversation.Language
+	}
+	session.Memory = conversation.Memory
+	session.UpdatedAt = time.Now()
+	return conversation, s.Store.Save(key, session)
+}
+
+// DialogText calls RequestClient.DialogText within the conversation stored for key
+// The conversation ID, memory and language are taken from the session unless set in opts
+// If there is no session yet, key is used as dialog conversation ID
+func (s *SessionClient) DialogText(key, text string, opts *DialogOpts) (Dialog, error) {
+	if key == "" {
+		return Dialog{}, ErrNoSessionKey
+	}
+	defer s.locks.lock(key)()
+	session, err := s.load(key)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	var o DialogOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.ConversationID == "" {
+		o.ConversationID = session.DialogConversationID
+	}
+	if o.ConversationID == "" {
+		o.ConversationID = key
+	}
+	if o.Language == "" {
+		o.Language = session.Language
+	}
+	if o.Memory == nil && len(session.Memory) > 0 {
+		o.Memory = session.Memory
+	}
+
+	dialog, err := s.Client.DialogText(text, &o)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	session.DialogConversationID = dialog.DialogConversation.ID
+	if dialog.DialogConversation.Language != "" {
+		session.Language = dialog.DialogConversation.Language
+	}
+	session.Memory = dialog.DialogConversation.Memory
+	session.UpdatedAt = time.Now()
+	return dialog, s.Store.Save(key, session)
+}
+
+// converseMemory returns the variables of memory in the format of ConverseOpts.Memory
+// Variables which are not objects cannot be sent to the converse endpoint and are left out
+func converseMemory(memory map[string]interface{}) map[string]map[string]interface{} {
+	converted := make(map[string]map[string]interface{}, len(memory))
+	for key, value := range memory {
+		if variable, ok := value.(map[string]interface{}); ok {
+			converted[key] = variable
+		}
+	}
+	return converted
+}
+
+// Session returns the session stored for key
+func (s *SessionClient) Session(key string) (Session, error) {
+	return s.load(key)
+}
+
+// Reset forgets the conversation stored for key, the next call starts a new conversation
+func (s *SessionClient) Reset(key string) error {
+	if key == "" {
+		return ErrNoSessionKey
+	}
+	return s.Store.Delete(key)
+}
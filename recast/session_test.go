@@ -0,0 +1,207 @@
+package recast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeRedis map[string][]byte
+
+func (r fakeRedis) Get(key string) ([]byte, bool, error) {
+	value, found := r[key]
+	return value, found, nil
+}
+
+func (r fakeRedis) Set(key string, value []byte, ttl time.Duration) error {
+	r[key] = value
+	return nil
+}
+
+func (r fakeRedis) Del(key string) error {
+	delete(r, key)
+	return nil
+}
+
+func testSessionStore(t *testing.T, store SessionStore) {
+	if _, found, err := store.Load("conversation_id"); found || err != nil {
+		t.Fatalf("Expected no session, but instead got found:%t err:%+v", found, err)
+	}
+
+	session := Session{ConversationToken: "token", Language: "fr", Memory: map[string]interface{}{"name": "Bob"}}
+	if err := store.Save("conversation_id", session); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	loaded, found, err := store.Load("conversation_id")
+	if err != nil || !found {
+		t.Fatalf("Expected a session, but instead got found:%t err:%+v", found, err)
+	}
+	if loaded.ConversationToken != "token" || loaded.Language != "fr" || loaded.Memory["name"] != "Bob" {
+		t.Fatalf("Unexpected session: %+v", loaded)
+	}
+
+	if err := store.Delete("conversation_id"); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, found, _ := store.Load("conversation_id"); found {
+		t.Fatal("The session should have been deleted")
+	}
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	testSessionStore(t, NewMemorySessionStore())
+}
+
+func TestRedisSessionStore(t *testing.T) {
+	redis := fakeRedis{}
+	testSessionStore(t, &RedisSessionStore{Client: redis, Prefix: "bot:"})
+
+	store := &RedisSessionStore{Client: redis, Prefix: "bot:"}
+	store.Save("key", Session{})
+	if _, found := redis["bot:key"]; !found {
+		t.Fatal("Sessions should be stored with the prefix")
+	}
+}
+
+func TestFileSessionStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sessions.json")
+
+	store, err := NewFileSessionStore(path)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	testSessionStore(t, store)
+
+	store.Save("persisted", Session{ConversationToken: "token"})
+	reopened, err := NewFileSessionStore(path)
+	if err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if session, found, _ := reopened.Load("persisted"); !found || session.ConversationToken != "token" {
+		t.Fatalf("Sessions should survive a reopening: %+v", session)
+	}
+}
+
+func TestSessionClientConverseText(t *testing.T) {
+	var tokens []string
+	var memories []map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form requestForms
+		json.NewDecoder(r.Body).Decode(&form)
+		tokens = append(tokens, form.ConversationToken)
+		memories = append(memories, form.Memory)
+		fmt.Fprintf(w, `{"results":{"conversation_token":"token-%d","language":"fr","memory":{"turn":{"raw":"%d"}}},"message":"Converses rendered with success"}`, len(tokens), len(tokens))
+	}))
+	defer server.Close()
+
+	sessions := SessionClient{
+		Client: &RequestClient{Token: "recast_token", Endpoint: server.URL},
+		Store:  NewMemorySessionStore(),
+	}
+
+	if _, err := sessions.ConverseText("conversation_id", "Hello", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if _, err := sessions.ConverseText("conversation_id", "How are you?", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	if tokens[0] != "" || tokens[1] != "token-1" {
+		t.Fatalf("The conversation token should be threaded between calls: %v", tokens)
+	}
+	if memories[0] != nil || memories[1]["turn"]["raw"] != "1" {
+		t.Fatalf("The memory should be threaded between calls: %v", memories)
+	}
+
+	session, _ := sessions.Session("conversation_id")
+	if session.ConversationToken != "token-2" || session.Language != "fr" || session.Memory["turn"].(map[string]interface{})["raw"] != "2" {
+		t.Fatalf("Unexpected session: %+v", session)
+	}
+
+	sessions.Reset("conversation_id")
+	sessions.ConverseText("conversation_id", "Hello", nil)
+	if tokens[2] != "" {
+		t.Fatal("A reset session should start a new conversation")
+	}
+
+	if _, err := sessions.ConverseText("", "Hello", nil); err != ErrNoSessionKey {
+		t.Fatalf("Expected ErrNoSessionKey, but instead got %+v", err)
+	}
+}
+
+func TestSessionClientDialogText(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form dialogForm
+		json.NewDecoder(r.Body).Decode(&form)
+		ids = append(ids, form.ConversationID)
+		fmt.Fprintf(w, `{"results":{"messages":[],"conversation":{"id":"%s","language":"en","memory":{}},"nlp":{}},"message":"Dialog rendered with success"}`, form.ConversationID)
+	}))
+	defer server.Close()
+
+	sessions := SessionClient{
+		Client: &RequestClient{Token: "recast_token", Endpoint: server.URL},
+		Store:  NewMemorySessionStore(),
+	}
+
+	if _, err := sessions.DialogText("conversation_id", "Hello", nil); err != nil {
+		t.Fatalf("Expected err to be nil, but instead got %+v", err)
+	}
+	session, _ := sessions.Session("conversation_id")
+	if ids[0] != "conversation_id" || session.DialogConversationID != "conversation_id" || session.Language != "en" {
+		t.Fatalf("Unexpected session: %+v", session)
+	}
+}
+
+func TestSessionClientConcurrentTurns(t *testing.T) {
+	var mu sync.Mutex
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form requestForms
+		json.NewDecoder(r.Body).Decode(&form)
+		mu.Lock()
+		tokens = append(tokens, form.ConversationToken)
+		n := len(tokens)
+		mu.Unlock()
+		fmt.Fprintf(w, `{"results":{"conversation_token":"token-%d"},"message":"Converses rendered with success"}`, n)
+	}))
+	defer server.Close()
+
+	sessions := SessionClient{
+		Client: &RequestClient{Token: "recast_token", Endpoint: server.URL},
+		Store:  NewMemorySessionStore(),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sessions.ConverseText("conversation_id", "Hello", nil); err != nil {
+				t.Errorf("Expected err to be nil, but instead got %+v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// each turn continues the conversation of the previous one
+	for i, token := range tokens[1:] {
+		if token != fmt.Sprintf("token-%d", i+1) {
+			t.Fatalf("The turns of a key should not overlap: %v", tokens)
+		}
+	}
+	if len(sessions.locks.locks) != 0 {
+		t.Fatal("The locks of the keys should be released")
+	}
+}
@@ -0,0 +1,400 @@
+package recast
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+)
+
+// ChannelLimits describes what a channel can display
+// A zero limit means that the channel does not enforce any
+type ChannelLimits struct {
+	// Channel is the type of channel the limits apply to, it is empty for the default limits
+	Channel string
+	// MaxTextLength is the number of characters of a text message
+	MaxTextLength int
+	// MaxTitleLength and MaxSubtitleLength apply to cards, carousel cards and list elements
+	MaxTitleLength    int
+	MaxSubtitleLength int
+	// MaxButtonTitleLength applies to all buttons and quick replies
+	MaxButtonTitleLength int
+	// MaxButtons is the number of buttons of a card, a carousel card or a buttons message
+	MaxButtons int
+	// MaxQuickReplies is the number of choices of a quick replies message
+	MaxQuickReplies int
+	// MaxCarouselCards is the number of cards of a carousel
+	MaxCarouselCards int
+	// MinListElements and MaxListElements bound the number of elements of a list
+	MinListElements int
+	MaxListElements int
+	// MaxListElementButtons is the number of buttons of each list element
+	MaxListElementButtons int
+	// MaxListButtons is the number of buttons at the bottom of a list
+	MaxListButtons int
+	// ButtonTypes are the button types supported by the channel, all known types are accepted if it is empty
+	ButtonTypes []ButtonType
+}
+
+var (
+	// DefaultLimits are the limits of the Recast.AI connector message formats
+	// They are used for the channels without specific limits
+	DefaultLimits = ChannelLimits{
+		MaxTitleLength:        80,
+		MaxSubtitleLength:     80,
+		MaxButtons:            3,
+		MaxQuickReplies:       11,
+		MaxCarouselCards:      10,
+		MinListElements:       2,
+		MaxListElements:       4,
+		MaxListElementButtons: 1,
+		MaxListButtons:        1,
+		ButtonTypes:           []ButtonType{ButtonPostback, ButtonURL, ButtonPhone, ButtonShare, ButtonLogin},
+	}
+
+	// MessengerLimits are the limits of Facebook Messenger templates
+	MessengerLimits = ChannelLimits{
+		Channel:               ChannelMessenger,
+		MaxTextLength:         2000,
+		MaxTitleLength:        80,
+		MaxSubtitleLength:     80,
+		MaxButtonTitleLength:  20,
+		MaxButtons:            3,
+		MaxQuickReplies:       11,
+		MaxCarouselCards:      10,
+		MinListElements:       2,
+		MaxListElements:       4,
+		MaxListElementButtons: 1,
+		MaxListButtons:        1,
+		ButtonTypes:           []ButtonType{ButtonPostback, ButtonURL, ButtonPhone, ButtonShare, ButtonLogin},
+	}
+
+	// SlackLimits are the limits of Slack message attachments
+	SlackLimits = ChannelLimits{
+		Channel:              ChannelSlack,
+		MaxTextLength:        4000,
+		MaxTitleLength:       150,
+		MaxSubtitleLength:    3000,
+		MaxButtonTitleLength: 75,
+		MaxButtons:           5,
+		MaxQuickReplies:      5,
+		MaxCarouselCards:     20,
+		MaxListElements:      20,
+		ButtonTypes:          []ButtonType{ButtonPostback, ButtonURL},
+	}
+
+	// TelegramLimits are the limits of Telegram messages and inline keyboards
+	TelegramLimits = ChannelLimits{
+		Channel:              ChannelTelegram,
+		MaxTextLength:        4096,
+		MaxTitleLength:       1024,
+		MaxSubtitleLength:    1024,
+		MaxButtonTitleLength: 64,
+		MaxButtons:           8,
+		MaxQuickReplies:      12,
+		MaxCarouselCards:     10,
+		MaxListElements:      10,
+		ButtonTypes:          []ButtonType{ButtonPostback, ButtonURL},
+	}
+
+	// WebchatLimits are the limits of the Recast.AI webchat
+	WebchatLimits = ChannelLimits{
+		Channel:        ChannelWebchat,
+		MaxTitleLength: 80,
+		ButtonTypes:    []ButtonType{ButtonPostback, ButtonURL, ButtonPhone},
+	}
+)
+
+// LimitsFor returns the limits of a channel type
+// DefaultLimits are returned for the channels without specific limits
+func LimitsFor(channel string) ChannelLimits {
+	switch channel {
+	case ChannelMessenger:
+		return MessengerLimits
+	case ChannelSlack, ChannelSlackWebhook:
+		return SlackLimits
+	case ChannelTelegram:
+		return TelegramLimits
+	case ChannelWebchat:
+		return WebchatLimits
+	}
+	return DefaultLimits
+}
+
+// Validator is implemented by the components which can be checked before being sent
+// All the components of the SDK implement it
+type Validator interface {
+	// Validate checks the component against the default connector limits
+	Validate() error
+	// ValidateFor checks the component against the limits of a channel type
+	ValidateFor(channel string) error
+}
+
+// ValidateMessages checks the messages implementing Validator against the limits of channel
+// The default limits are used when channel is empty
+func ValidateMessages(channel string, messages ...Component) error {
+	for _, message := range messages {
+		if v, ok := message.(Validator); ok {
+			if err := v.ValidateFor(channel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// limitChecker accumulates the first error found while checking a component
+type limitChecker struct {
+	limits    ChannelLimits
+	component string
+	err       error
+}
+
+func newLimitChecker(channel, component string) *limitChecker {
+	return &limitChecker{limits: LimitsFor(channel), component: component}
+}
+
+func (c *limitChecker) fail(format string, args ...interface{}) {
+	if c.err != nil {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	if c.limits.Channel != "" {
+		c.err = fmt.Errorf("Invalid %s for %s: %s", c.component, c.limits.Channel, message)
+		return
+	}
+	c.err = fmt.Errorf("Invalid %s: %s", c.component, message)
+}
+
+func (c *limitChecker) required(name, value string) {
+	if value == "" {
+		c.fail("%s is empty", name)
+	}
+}
+
+func (c *limitChecker) length(name, value string, max int) {
+	if max > 0 && utf8.RuneCountInString(value) > max {
+		c.fail("%s is longer than %d characters", name, max)
+	}
+}
+
+func (c *limitChecker) count(name string, n, min, max int) {
+	if n < min {
+		c.fail("%d %s, at least %d are required", n, name, min)
+	}
+	if max > 0 && n > max {
+		c.fail("%d %s, at most %d are allowed", n, name, max)
+	}
+}
+
+func (c *limitChecker) url(name, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		c.fail("%s is not a valid URL: %s", name, value)
+	}
+}
+
+func (c *limitChecker) title(title, subtitle, imageURL string) {
+	c.required("title", title)
+	c.length("title", title, c.limits.MaxTitleLength)
+	c.length("subtitle", subtitle, c.limits.MaxSubtitleLength)
+	if imageURL != "" {
+		c.url("image", imageURL)
+	}
+}
+
+func (c *limitChecker) button(b CardButton) {
+	c.required("button title", b.Title)
+	c.length("button title", b.Title, c.limits.MaxButtonTitleLength)
+	kind := b.Kind()
+	if kind == "" || (len(c.limits.ButtonTypes) > 0 && !containsButtonType(c.limits.ButtonTypes, kind)) {
+		c.fail("unsupported button type %q", b.Type)
+	}
+	switch kind {
+	case ButtonURL, ButtonLogin:
+		c.url("button value", b.Value)
+	case ButtonPhone:
+		if !phoneRegexp.MatchString(b.Value) {
+			c.fail("button value is not a valid phone number: %s", b.Value)
+		}
+	case ButtonShare:
+	default:
+		c.required("button value", b.Value)
+	}
+}
+
+func (c *limitChecker) buttons(buttons []CardButton, max int) {
+	c.count("buttons", len(buttons), 0, max)
+	for _, b := range buttons {
+		c.button(b)
+	}
+}
+
+func containsButtonType(values []ButtonType, t ButtonType) bool {
+	for _, v := range values {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate implements Validator
+func (c Attachment) Validate() error {
+	return c.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (c Attachment) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, c.Type+" message")
+	switch c.Type {
+	case "text":
+		check.required("text", c.Content)
+		check.length("text", c.Content, check.limits.MaxTextLength)
+	case "picture", "video", "audio", "file":
+		check.url("content", c.Content)
+	default:
+		check.component = "attachment"
+		check.fail("unknown type %q", c.Type)
+	}
+	return check.err
+}
+
+// Validate implements Validator
+func (c *Card) Validate() error {
+	return c.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (c *Card) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "card")
+	check.title(c.Content.Title, c.Content.Subtitle, c.Content.ImageURL)
+	check.buttons(c.Content.Buttons, check.limits.MaxButtons)
+	return check.err
+}
+
+// Validate implements Validator
+func (c *Carousel) Validate() error {
+	return c.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (c *Carousel) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "carousel")
+	check.count("cards", len(c.Content), 1, check.limits.MaxCarouselCards)
+	for _, card := range c.Content {
+		check.title(card.Title, card.Subtitle, card.ImageURL)
+		check.buttons(card.Buttons, check.limits.MaxButtons)
+	}
+	return check.err
+}
+
+// Validate implements Validator
+func (l *List) Validate() error {
+	return l.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (l *List) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "list")
+	check.count("elements", len(l.Content.Elements), check.limits.MinListElements, check.limits.MaxListElements)
+	for _, e := range l.Content.Elements {
+		check.title(e.Title, e.Subtitle, e.ImageURL)
+		check.buttons(listButtons(e.Buttons), check.limits.MaxListElementButtons)
+	}
+	check.buttons(listButtons(l.Content.Buttons), check.limits.MaxListButtons)
+	return check.err
+}
+
+func listButtons(buttons []ListButton) []CardButton {
+	converted := make([]CardButton, len(buttons))
+	for i, b := range buttons {
+		converted[i] = CardButton(b)
+	}
+	return converted
+}
+
+// Validate implements Validator
+func (q *QuickReplies) Validate() error {
+	return q.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (q *QuickReplies) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "quick replies")
+	check.required("title", q.Content.Title)
+	check.length("title", q.Content.Title, check.limits.MaxTextLength)
+	check.count("quick replies", len(q.Content.Buttons), 1, check.limits.MaxQuickReplies)
+	for _, b := range q.Content.Buttons {
+		check.required("quick reply title", b.Title)
+		check.length("quick reply title", b.Title, check.limits.MaxButtonTitleLength)
+		check.required("quick reply value", b.Value)
+	}
+	return check.err
+}
+
+// Validate implements Validator
+func (b *Buttons) Validate() error {
+	return b.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (b *Buttons) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "buttons")
+	check.required("title", b.Content.Title)
+	check.length("title", b.Content.Title, check.limits.MaxTextLength)
+	check.count("buttons", len(b.Content.Buttons), 1, check.limits.MaxButtons)
+	check.buttons(b.Content.Buttons, 0)
+	return check.err
+}
+
+// Validate implements Validator
+func (a *Audio) Validate() error {
+	return a.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (a *Audio) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "audio message")
+	check.url("content", a.Content)
+	return check.err
+}
+
+// Validate implements Validator
+func (f *File) Validate() error {
+	return f.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (f *File) ValidateFor(channel string) error {
+	check := newLimitChecker(channel, "file message")
+	check.url("content", f.Content)
+	return check.err
+}
+
+// Validate implements Validator
+func (d *Delay) Validate() error {
+	return d.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+func (d *Delay) ValidateFor(channel string) error {
+	if d.Content < 0 {
+		return fmt.Errorf("Invalid delay: %g seconds", d.Content)
+	}
+	return nil
+}
+
+// Validate implements Validator
+func (c *RawComponent) Validate() error {
+	return c.ValidateFor("")
+}
+
+// ValidateFor implements Validator
+// The content of a RawComponent is not checked
+func (c *RawComponent) ValidateFor(channel string) error {
+	if c.Type == "" {
+		return errors.New("Invalid message: type is empty")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package recast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	list := NewList()
+	for i := 0; i < 5; i++ {
+		list.AddElement(NewListElement("element", "subtitle"))
+	}
+
+	tests := []struct {
+		name      string
+		component Validator
+		channel   string
+		err       string
+	}{
+		{"text", NewTextMessage("Hello"), "", ""},
+		{"empty text", NewTextMessage(""), "", "Invalid text message: text is empty"},
+		{"long text", NewTextMessage(strings.Repeat("a", 2001)), ChannelMessenger, "Invalid text message for messenger: text is longer than 2000 characters"},
+		{"picture", Attachment{Type: "picture", Content: "not an url"}, "", "Invalid picture message: content is not a valid URL: not an url"},
+		{"card", NewCard("title", "subtitle").AddButton("Yes", "postback", "yes"), "", ""},
+		{"card without title", NewCard("", "subtitle"), "", "Invalid card: title is empty"},
+		{"card buttons", NewCard("title", "").AddButton("1", "postback", "1").AddButton("2", "postback", "2").AddButton("3", "postback", "3").AddButton("4", "postback", "4"), "", "Invalid card: 4 buttons, at most 3 are allowed"},
+		{"card buttons on slack", NewCard("title", "").AddButton("1", "postback", "1").AddButton("2", "postback", "2").AddButton("3", "postback", "3").AddButton("4", "postback", "4"), ChannelSlack, ""},
+		{"button type", NewCard("title", "").AddButton("Call", "phone_number", "+33600000000"), ChannelTelegram, `Invalid card for telegram: unsupported button type "phone_number"`},
+		{"button typo", NewCard("title", "").AddButton("Go", "weburl", "https://recast.ai"), "", `Invalid card: unsupported button type "weburl"`},
+		{"button url", NewCard("title", "").AddButton("Go", "web_url", "recast.ai"), "", "Invalid card: button value is not a valid URL: recast.ai"},
+		{"image url", NewCard("title", "").AddImage("image"), "", "Invalid card: image is not a valid URL: image"},
+		{"button title", NewButtons("Choose").AddButton("A very long button title", "postback", "long"), ChannelMessenger, "Invalid buttons for messenger: button title is longer than 20 characters"},
+		{"empty carousel", NewCarousel(), "", "Invalid carousel: 0 cards, at least 1 are required"},
+		{"list elements", list, "", "Invalid list: 5 elements, at most 4 are allowed"},
+		{"list on slack", list, ChannelSlack, ""},
+		{"list on slack webhook", list, ChannelSlackWebhook, ""},
+		{"list element buttons", NewList().AddElement(NewListElement("a", "").AddButton("1", "postback", "1").AddButton("2", "postback", "2")).AddElement(NewListElement("b", "")), "", "Invalid list: 2 buttons, at most 1 are allowed"},
+		{"quick replies", NewQuickReplies("Question?").AddButton("Yes", "yes"), "", ""},
+		{"no quick replies", NewQuickReplies("Question?"), "", "Invalid quick replies: 0 quick replies, at least 1 are required"},
+		{"audio", NewAudio("https://example.com/song.mp3"), "", ""},
+		{"file", NewFile("doc.pdf"), "", "Invalid file message: content is not a valid URL: doc.pdf"},
+		{"delay", &Delay{Type: "delay", Content: -1}, "", "Invalid delay: -1 seconds"},
+		{"raw", &RawComponent{}, "", "Invalid message: type is empty"},
+	}
+
+	for _, test := range tests {
+		err := test.component.ValidateFor(test.channel)
+		if test.err == "" && err != nil {
+			t.Fatalf("%s: expected err to be nil, but instead got %+v", test.name, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Fatalf("%s: expected err to be %q, but instead got %+v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestSendMessageValidation(t *testing.T) {
+	client := &ConnectClient{Token: "recast_token", ValidateMessages: true}
+	err := client.SendMessage("conversation_id", NewTextMessage("Hello"), NewCard("", ""))
+	if err == nil || err.Error() != "Invalid card: title is empty" {
+		t.Fatalf("The messages should be validated before being sent, got %+v", err)
+	}
+
+	writer := &messageWriter{client: client, context: &Context{ConversationID: "conversation_id", ChannelType: ChannelTelegram}}
+	err = writer.Reply(NewCard("title", "").AddButton("Call", "phone_number", "+33600000000"))
+	if err == nil || !strings.Contains(err.Error(), "for telegram") {
+		t.Fatalf("Replies should be validated for the conversation channel, got %+v", err)
+	}
+}